@@ -0,0 +1,113 @@
+package pgr
+
+import (
+	"context"
+	"hash/fnv"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// AdvisoryKey hashes a name into an advisory lock key.
+func AdvisoryKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
+
+// AdvisoryLock is a session-level advisory lock. It is held on a dedicated
+// connection until Unlock is called.
+type AdvisoryLock struct {
+	key     int64
+	conn    Conn
+	release func()
+}
+
+// AdvisoryLock waits for and acquires the session-level advisory lock of key.
+func (db *Pgr) AdvisoryLock(ctx context.Context, key int64) (*AdvisoryLock, error) {
+	conn, release, err := db.acquire(ctx)
+	if err != nil {
+		return nil, pgError(err)
+	}
+	_, err = conn.Exec(ctx, "SELECT pg_advisory_lock($1)", key)
+	if err != nil {
+		release()
+		return nil, pgError(err)
+	}
+	return &AdvisoryLock{
+		key:     key,
+		conn:    conn,
+		release: release,
+	}, nil
+}
+
+// TryAdvisoryLock acquires the session-level advisory lock of key if it is
+// available, and reports whether it was.
+func (db *Pgr) TryAdvisoryLock(ctx context.Context, key int64) (*AdvisoryLock, bool, error) {
+	conn, release, err := db.acquire(ctx)
+	if err != nil {
+		return nil, false, pgError(err)
+	}
+	var ok bool
+	err = conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&ok)
+	if err != nil || !ok {
+		release()
+		return nil, false, pgError(err)
+	}
+	return &AdvisoryLock{
+		key:     key,
+		conn:    conn,
+		release: release,
+	}, true, nil
+}
+
+// Unlock releases the lock and its connection. If the lock can't be
+// released, a pooled connection is closed, which releases the lock.
+func (l *AdvisoryLock) Unlock(ctx context.Context) error {
+	defer l.release()
+	_, err := l.conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", l.key)
+	if err != nil {
+		if conn, ok := l.conn.(*pgxpool.Conn); ok {
+			conn.Conn().Close(context.Background())
+		}
+	}
+	return pgError(err)
+}
+
+// WithAdvisoryLock runs fn while holding the session-level advisory lock of
+// key. The lock is released even if fn panics.
+func (db *Pgr) WithAdvisoryLock(ctx context.Context, key int64, fn func(ctx context.Context) error) (err error) {
+	lock, err := db.AdvisoryLock(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		unlockErr := lock.Unlock(ctx)
+		if err == nil {
+			err = unlockErr
+		}
+	}()
+	return fn(ctx)
+}
+
+// AdvisoryXactLock waits for and acquires the transaction-level advisory lock
+// of key, which is released when the transaction of the context ends.
+func (db *Pgr) AdvisoryXactLock(ctx context.Context, key int64) error {
+	tx := getTransaction(ctx)
+	if tx == nil {
+		return ErrNotInTransaction
+	}
+	_, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", key)
+	return pgError(err)
+}
+
+// TryAdvisoryXactLock acquires the transaction-level advisory lock of key if
+// it is available, and reports whether it was.
+func (db *Pgr) TryAdvisoryXactLock(ctx context.Context, key int64) (bool, error) {
+	tx := getTransaction(ctx)
+	if tx == nil {
+		return false, ErrNotInTransaction
+	}
+	var ok bool
+	err := tx.QueryRow(ctx, "SELECT pg_try_advisory_xact_lock($1)", key).Scan(&ok)
+	return ok, pgError(err)
+}
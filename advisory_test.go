@@ -0,0 +1,48 @@
+package pgr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdvisoryLock(t *testing.T) {
+	db := getDb()
+	ctx := context.Background()
+	key := AdvisoryKey("tenant:1")
+	require.Equal(t, key, AdvisoryKey("tenant:1"))
+	require.NotEqual(t, key, AdvisoryKey("tenant:2"))
+
+	ran := false
+	err := db.WithAdvisoryLock(ctx, key, func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+	require.NoError(t, err)
+	require.True(t, ran)
+
+	require.Panics(t, func() {
+		db.WithAdvisoryLock(ctx, key, func(ctx context.Context) error {
+			panic("failed")
+		})
+	})
+	held, err := Scalar[int](ctx, db.SelectSql("SELECT count(*) FROM pg_locks WHERE locktype = 'advisory' AND pid = pg_backend_pid()"))
+	require.NoError(t, err)
+	require.Equal(t, 0, held)
+
+	lock, ok, err := db.TryAdvisoryLock(ctx, key)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, lock.Unlock(ctx))
+
+	err = db.AdvisoryXactLock(ctx, key)
+	require.ErrorIs(t, err, ErrNotInTransaction)
+
+	err = db.Transaction(ctx, func(ctx context.Context) error {
+		ok, err := db.TryAdvisoryXactLock(ctx, key)
+		require.True(t, ok)
+		return err
+	})
+	require.NoError(t, err)
+}
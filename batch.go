@@ -0,0 +1,159 @@
+package pgr
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// Batch queues statements to send them to the database in a single round trip.
+type Batch struct {
+	db    *Pgr
+	items []*BatchItem
+}
+
+// BatchItem is a statement queued in a Batch.
+// Its result is set once the batch is sent.
+type BatchItem struct {
+	builder Builder
+	dest    interface{}
+
+	// RowsAffected is the number of rows affected by a statement queued with Exec.
+	RowsAffected int64
+	// Count is the number of rows loaded by a statement queued with Load.
+	Count int
+	// Err is the error of the statement.
+	Err error
+}
+
+// Batch creates an empty Batch.
+func (db *Pgr) Batch() *Batch {
+	return &Batch{
+		db: db,
+	}
+}
+
+// Exec queues a statement whose rows affected are stored in the item.
+func (b *Batch) Exec(builder Builder) *BatchItem {
+	item := &BatchItem{
+		builder: builder,
+	}
+	b.items = append(b.items, item)
+	return item
+}
+
+// Load queues a statement whose rows are loaded into dest, like SelectBuilder.Load.
+func (b *Batch) Load(builder Builder, dest interface{}) *BatchItem {
+	item := &BatchItem{
+		builder: builder,
+		dest:    dest,
+	}
+	b.items = append(b.items, item)
+	return item
+}
+
+// Len returns the number of queued statements.
+func (b *Batch) Len() int {
+	return len(b.items)
+}
+
+// Send sends all queued statements in a single round trip, using the
+// transaction of the context if any, and sets the result of each item.
+// It returns the first error of the statements.
+//
+// The statements run in one implicit transaction: when one fails,
+// the following ones fail too.
+//
+// The statement timeouts of the builders are enforced by the server, and
+// reported as ErrStatementTimeout, but the batch has no client deadline.
+//
+// The statements all run with ctx: the contexts returned by the BeforeQuery
+// of hooks are only given to their AfterQuery.
+func (b *Batch) Send(ctx context.Context) (err error) {
+	ctx, span := b.db.startSpan(ctx, "pgr.batch",
+		Attribute{AttrOperation, "batch"},
+		Attribute{AttrBatchSize, len(b.items)},
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	type queuedItem struct {
+		*BatchItem
+		ctx     context.Context
+		event   *QueryEvent
+		timeout bool
+	}
+
+	batch := &pgx.Batch{}
+	var queued []queuedItem
+	var firstErr error
+	for _, item := range b.items {
+		query, values, err := b.db.build(ctx, item.builder)
+		var event *QueryEvent
+		var itemCtx context.Context
+		if err == nil {
+			itemCtx, event, err = b.db.before(ctx, item.builder, query, values)
+		}
+		if err != nil {
+			item.Err = err
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		timeout := b.db.statementTimeout(ctx, item.builder)
+		queueTimeout(batch, timeout, query, values)
+		queued = append(queued, queuedItem{item, itemCtx, event, timeout > 0})
+	}
+	if len(queued) == 0 {
+		return firstErr
+	}
+
+	results := b.db.connFor(ctx).SendBatch(ctx, batch)
+	for _, item := range queued {
+		var setErr error
+		if item.timeout {
+			_, setErr = results.Exec()
+		}
+		if item.dest != nil {
+			rows, err := results.Query()
+			if err == nil {
+				item.Count, err = Load(rows, item.dest)
+			}
+			item.event.RowsAffected = int64(item.Count)
+			item.Err = err
+		} else {
+			tag, err := results.Exec()
+			item.RowsAffected = tag.RowsAffected()
+			item.event.RowsAffected = item.RowsAffected
+			item.Err = err
+		}
+		if item.timeout {
+			results.Exec()
+		}
+		if setErr != nil {
+			item.Err = setErr
+		}
+		item.Err = pgError(b.db.wrapTimeout(ctx, item.builder, item.Err))
+		item.event.Err = item.Err
+		b.db.after(item.ctx, item.event)
+		if item.Err != nil && firstErr == nil {
+			firstErr = item.Err
+		}
+	}
+	err = pgError(results.Close())
+	if firstErr == nil {
+		firstErr = err
+	}
+	for _, item := range queued {
+		if _, ok := item.builder.(*SelectBuilder); !ok && item.Err == nil {
+			b.db.trackWrite(ctx)
+			break
+		}
+	}
+	return firstErr
+}
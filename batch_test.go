@@ -0,0 +1,48 @@
+package pgr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatch(t *testing.T) {
+	db := getDb()
+	ctx := context.Background()
+
+	batch := db.Batch()
+	insert := batch.Exec(db.InsertInto("users").
+		Columns("name", "age").
+		Values("a", 1).
+		Values("b", 2))
+	var users []User
+	load := batch.Load(db.Select("id", "name", "age").From("users").OrderAsc("id"), &users)
+	invalid := batch.Exec(db.Update("users").Set("age", 3))
+
+	err := batch.Send(ctx)
+	require.ErrorIs(t, err, ErrMissingWhere)
+	require.ErrorIs(t, invalid.Err, ErrMissingWhere)
+
+	require.NoError(t, insert.Err)
+	require.Equal(t, int64(2), insert.RowsAffected)
+
+	require.NoError(t, load.Err)
+	require.Equal(t, 2, load.Count)
+	require.Equal(t, "a", users[0].Name)
+	require.Equal(t, "b", users[1].Name)
+}
+
+func TestBatchTimeout(t *testing.T) {
+	db := getDb()
+	ctx := context.Background()
+
+	batch := db.Batch()
+	var slept []string
+	load := batch.Load(db.SelectSql("SELECT pg_sleep(1)::text").Timeout(10*time.Millisecond), &slept)
+
+	err := batch.Send(ctx)
+	require.ErrorIs(t, err, ErrStatementTimeout)
+	require.ErrorIs(t, load.Err, ErrStatementTimeout)
+}
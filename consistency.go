@@ -0,0 +1,141 @@
+package pgr
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+const consistencyKey contextKey = "consistency"
+
+const replicaPollInterval = 10 * time.Millisecond
+
+// LSN is a position in the write-ahead log of the primary. It is used as a
+// consistency token: a replica which replayed the LSN of a write can read it.
+type LSN uint64
+
+// ParseLSN parses the textual form of an LSN, like "16/B374D848".
+func ParseLSN(s string) (LSN, error) {
+	var hi, lo uint32
+	_, err := fmt.Sscanf(s, "%X/%X", &hi, &lo)
+	if err != nil {
+		return 0, err
+	}
+	return LSN(uint64(hi)<<32 | uint64(lo)), nil
+}
+
+// String returns the textual form of the LSN, suitable for a cookie.
+func (l LSN) String() string {
+	return fmt.Sprintf("%X/%X", uint32(l>>32), uint32(l))
+}
+
+type consistency struct {
+	lsn uint64
+}
+
+func (c *consistency) observe(lsn LSN) {
+	for {
+		old := atomic.LoadUint64(&c.lsn)
+		if uint64(lsn) <= old || atomic.CompareAndSwapUint64(&c.lsn, old, uint64(lsn)) {
+			return
+		}
+	}
+}
+
+// WithConsistency returns a context which provides read-your-writes
+// consistency for replica reads, starting from token, e.g. taken from a
+// cookie, or zero.
+//
+// Writes made with the context, or with a transaction begun with it, update
+// its token once they are committed. Reads made with the context only use
+// replicas which replayed the token.
+func WithConsistency(ctx context.Context, token LSN) context.Context {
+	return context.WithValue(ctx, consistencyKey, &consistency{lsn: uint64(token)})
+}
+
+// ConsistencyToken returns the latest token of a context created with
+// WithConsistency, or zero.
+func ConsistencyToken(ctx context.Context) LSN {
+	if c := getConsistency(ctx); c != nil {
+		return LSN(atomic.LoadUint64(&c.lsn))
+	}
+	return 0
+}
+
+func getConsistency(ctx context.Context) *consistency {
+	c, _ := ctx.Value(consistencyKey).(*consistency)
+	return c
+}
+
+// CaptureToken returns the current LSN of the primary, and stores it in the
+// context if it was created with WithConsistency.
+func (p *Pgr) CaptureToken(ctx context.Context) (LSN, error) {
+	var s string
+	err := p.conn.QueryRow(ctx, "SELECT pg_current_wal_lsn()::text").Scan(&s)
+	if err != nil {
+		return 0, pgError(err)
+	}
+	lsn, err := ParseLSN(s)
+	if err != nil {
+		return 0, err
+	}
+	if c := getConsistency(ctx); c != nil {
+		c.observe(lsn)
+	}
+	return lsn, nil
+}
+
+// trackWrite captures the token after a write made outside of a transaction,
+// if the context tracks consistency.
+func (p *Pgr) trackWrite(ctx context.Context) {
+	if getConsistency(ctx) == nil || getTxState(ctx) != nil {
+		return
+	}
+	_, err := p.CaptureToken(ctx)
+	if err != nil {
+		p.logger.Log(ctx, LogLevelWarn, "capture consistency token failed", ErrorField(err))
+	}
+}
+
+// caughtUp reports whether the replica replayed the token of the context,
+// waiting for it up to the ReplicaWaitTimeout of the Config.
+func (p *Pgr) caughtUp(ctx context.Context, r *replica) bool {
+	token := ConsistencyToken(ctx)
+	if token == 0 || LSN(atomic.LoadUint64(&r.replayed)) >= token {
+		return true
+	}
+
+	deadline := time.Now().Add(p.replicaWait)
+	for {
+		var s string
+		err := r.conn.QueryRow(ctx, "SELECT COALESCE(pg_last_wal_replay_lsn(), pg_current_wal_lsn())::text").Scan(&s)
+		if err != nil {
+			if connFailed(ctx, err) {
+				p.replicas.failed(r)
+			}
+			return false
+		}
+		lsn, err := ParseLSN(s)
+		if err != nil {
+			return false
+		}
+		atomic.StoreUint64(&r.replayed, uint64(lsn))
+		if lsn >= token {
+			return true
+		}
+
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return false
+		}
+		if wait > replicaPollInterval {
+			wait = replicaPollInterval
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(wait):
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package pgr
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLSN(t *testing.T) {
+	lsn, err := ParseLSN("16/B374D848")
+	require.NoError(t, err)
+	require.Equal(t, LSN(0x16B374D848), lsn)
+	require.Equal(t, "16/B374D848", lsn.String())
+
+	_, err = ParseLSN("invalid")
+	require.Error(t, err)
+}
+
+func TestConsistencyToken(t *testing.T) {
+	ctx := context.Background()
+	require.Equal(t, LSN(0), ConsistencyToken(ctx))
+
+	ctx = WithConsistency(ctx, 10)
+	c := getConsistency(ctx)
+	c.observe(20)
+	c.observe(15)
+	require.Equal(t, LSN(20), ConsistencyToken(ctx))
+}
+
+type lsnRow struct {
+	lsn string
+	err error
+}
+
+func (r lsnRow) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	*dest[0].(*string) = r.lsn
+	return nil
+}
+
+// lsnConn reports its LSNs in turn, then the last one repeatedly.
+type lsnConn struct {
+	Conn
+	lsns  []string
+	err   error
+	calls int
+}
+
+func (c *lsnConn) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	i := c.calls
+	if i >= len(c.lsns) {
+		i = len(c.lsns) - 1
+	}
+	c.calls++
+	if c.err != nil {
+		return lsnRow{err: c.err}
+	}
+	return lsnRow{lsn: c.lsns[i]}
+}
+
+func (c *lsnConn) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag("UPDATE 1"), nil
+}
+
+func (c *lsnConn) Begin(ctx context.Context) (pgx.Tx, error) {
+	return &lsnTx{conn: c}, nil
+}
+
+func (c *lsnConn) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return lsnBatchResults{}
+}
+
+type lsnTx struct {
+	pgx.Tx
+	conn *lsnConn
+}
+
+func (tx *lsnTx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return tx.conn.Exec(ctx, sql, args...)
+}
+
+func (tx *lsnTx) Commit(ctx context.Context) error   { return nil }
+func (tx *lsnTx) Rollback(ctx context.Context) error { return pgx.ErrTxClosed }
+
+type lsnBatchResults struct {
+	pgx.BatchResults
+}
+
+func (lsnBatchResults) Exec() (pgconn.CommandTag, error) { return pgconn.CommandTag("UPDATE 1"), nil }
+func (lsnBatchResults) Close() error                     { return nil }
+
+func TestReadYourWrites(t *testing.T) {
+	t.Run("track writes", func(t *testing.T) {
+		primary := &lsnConn{lsns: []string{"0/100"}}
+		db, err := New(primary, nil)
+		require.NoError(t, err)
+		ctx := WithConsistency(context.Background(), 0)
+
+		_, err = db.UpdateSql("UPDATE users SET age = 1 WHERE id = 1").Exec(ctx)
+		require.NoError(t, err)
+		require.Equal(t, LSN(0x100), ConsistencyToken(ctx))
+
+		primary.lsns = []string{"0/200"}
+		batch := db.Batch()
+		batch.Exec(db.DeleteFrom("users").Where(Eq("id", 1)))
+		require.NoError(t, batch.Send(ctx))
+		require.Equal(t, LSN(0x200), ConsistencyToken(ctx))
+
+		primary.lsns = []string{"0/300"}
+		calls := primary.calls
+		err = db.Transaction(ctx, func(ctx context.Context) error {
+			_, err := db.UpdateSql("UPDATE users SET age = 2 WHERE id = 1").Exec(ctx)
+			require.NoError(t, err)
+			// not tracked until the transaction commits
+			require.Equal(t, calls, primary.calls)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, LSN(0x300), ConsistencyToken(ctx))
+	})
+
+	t.Run("wait for replica", func(t *testing.T) {
+		replica := &lsnConn{lsns: []string{"0/100", "0/200", "0/300"}}
+		db, err := New(&lsnConn{}, &Config{
+			Replicas:           []Conn{replica},
+			ReplicaWaitTimeout: time.Second,
+		})
+		require.NoError(t, err)
+
+		require.NotNil(t, db.readReplica(context.Background(), db.Select("1")))
+		require.Equal(t, 0, replica.calls)
+
+		ctx := WithConsistency(context.Background(), 0x300)
+		require.NotNil(t, db.readReplica(ctx, db.Select("1")))
+		require.Equal(t, 3, replica.calls)
+
+		// the replayed LSN is remembered
+		require.NotNil(t, db.readReplica(ctx, db.Select("1")))
+		require.Equal(t, 3, replica.calls)
+	})
+
+	t.Run("fall back to primary", func(t *testing.T) {
+		replica := &lsnConn{lsns: []string{"0/100"}}
+		db, err := New(&lsnConn{}, &Config{
+			Replicas:           []Conn{replica},
+			ReplicaWaitTimeout: 30 * time.Millisecond,
+		})
+		require.NoError(t, err)
+		ctx := WithConsistency(context.Background(), 0x200)
+
+		start := time.Now()
+		require.Nil(t, db.readReplica(ctx, db.Select("1")))
+		require.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+
+		db.replicaWait = 0
+		calls := replica.calls
+		require.Nil(t, db.readReplica(ctx, db.Select("1")))
+		require.Equal(t, calls+1, replica.calls)
+
+		replica.err = errors.New("connection refused")
+		require.Nil(t, db.readReplica(ctx, db.Select("1")))
+		require.Nil(t, db.replicas.pick())
+	})
+}
@@ -0,0 +1,52 @@
+package pgr
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// copyStatement describes a COPY to hooks, metrics and logs.
+type copyStatement struct {
+	table   string
+	columns []string
+}
+
+func (s *copyStatement) Build(buf Buffer) error {
+	buf.WriteString("COPY ")
+	buf.WriteString(QuoteIdent(s.table))
+	buf.WriteString(" (")
+	for i, col := range s.columns {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString(QuoteIdent(col))
+	}
+	buf.WriteString(") FROM STDIN")
+	return nil
+}
+
+// CopyFrom copies rows into table with the COPY protocol,
+// using the transaction of the context if any.
+func (db *Pgr) CopyFrom(ctx context.Context, table string, columns []string, src pgx.CopyFromSource) (int64, error) {
+	stmt := &copyStatement{table, columns}
+	query, values, err := db.build(ctx, stmt)
+	if err != nil {
+		return 0, err
+	}
+	ctx, event, err := db.before(ctx, stmt, query, values)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := db.connFor(ctx).CopyFrom(ctx, pgx.Identifier{table}, columns, src)
+	err = pgError(err)
+	event.RowsAffected = count
+	event.Err = err
+	db.after(ctx, event)
+
+	if err == nil {
+		db.trackWrite(ctx)
+	}
+	return count, err
+}
@@ -0,0 +1,46 @@
+package pgr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/require"
+)
+
+type copyConn struct {
+	Conn
+}
+
+func (copyConn) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	var count int64
+	for rowSrc.Next() {
+		count++
+	}
+	return count, rowSrc.Err()
+}
+
+func TestCopyFrom(t *testing.T) {
+	tracer := NewRecordingTracer()
+	hook := &recordingHook{name: "hook", calls: &[]string{}}
+	db, err := New(copyConn{}, &Config{Tracer: tracer, Hooks: []Hook{hook}})
+	require.NoError(t, err)
+
+	rows := [][]interface{}{{"a", 1}, {"b", 2}}
+	count, err := db.CopyFrom(context.Background(), "users", []string{"name", "age"}, pgx.CopyFromRows(rows))
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	require.Len(t, hook.events, 1)
+	require.Equal(t, `COPY "users" ("name","age") FROM STDIN`, hook.events[0].SQL)
+	require.Equal(t, int64(2), hook.events[0].RowsAffected)
+
+	spans := tracer.Spans()
+	require.Len(t, spans, 1)
+	require.Equal(t, "pgr.copy", spans[0].Name)
+	require.Equal(t, "users", spans[0].Attributes[AttrTable])
+
+	stats := db.Stats().Statements[StatementKey{"copy", "users"}]
+	require.Equal(t, int64(1), stats.Count)
+	require.Equal(t, int64(2), stats.Rows)
+}
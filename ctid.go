@@ -0,0 +1,47 @@
+package pgr
+
+import "strconv"
+
+// limitRows builds `(tableoid, ctid) IN (SELECT tableoid, ctid ...)` to
+// restrict an UPDATE or DELETE to the first rows matching the condition,
+// since postgres supports neither ORDER BY nor LIMIT for them. The ctid is
+// only unique within a partition, hence the tableoid.
+func limitRows(table string, cond, order []Builder, limit int64, skipLocked bool) Builder {
+	return BuildFunc(func(buf Buffer) error {
+		buf.WriteString("(tableoid, ctid) IN (SELECT tableoid, ctid FROM ")
+		buf.WriteString(QuoteIdent(table))
+
+		if len(cond) > 0 {
+			buf.WriteString(" WHERE ")
+			err := And(cond...).Build(buf)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(order) > 0 {
+			buf.WriteString(" ORDER BY ")
+			for i, order := range order {
+				if i > 0 {
+					buf.WriteString(", ")
+				}
+				err := order.Build(buf)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if limit >= 0 {
+			buf.WriteString(" LIMIT ")
+			buf.WriteString(strconv.FormatInt(limit, 10))
+		}
+
+		if skipLocked {
+			buf.WriteString(" FOR UPDATE SKIP LOCKED")
+		}
+
+		buf.WriteString(")")
+		return nil
+	})
+}
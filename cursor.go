@@ -0,0 +1,161 @@
+package pgr
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// Cursor reads the result of a query in batches through a server-side cursor.
+type Cursor struct {
+	db      *Pgr
+	builder *SelectBuilder
+	conn    Conn
+	tx      pgx.Tx
+	release func()
+	name    string
+	size    int
+}
+
+// CursorOption configures a Cursor.
+type CursorOption func(*cursorOptions)
+
+type cursorOptions struct {
+	hold bool
+}
+
+// WithHold declares the cursor WITH HOLD, outside of any transaction,
+// so that it can be read across transactions until it is closed.
+func WithHold() CursorOption {
+	return func(o *cursorOptions) {
+		o.hold = true
+	}
+}
+
+var cursorID uint64
+
+// Cursor declares a server-side cursor for the query, which fetches
+// batchSize records at a time. batchSize must be positive.
+//
+// The cursor uses the transaction of the context. If there is none,
+// it begins a transaction which ends when the cursor is closed.
+// The statements of the cursor go through the hooks, and each fetch is
+// subject to the statement timeout of the builder.
+func (b *SelectBuilder) Cursor(ctx context.Context, batchSize int, opts ...CursorOption) (*Cursor, error) {
+	if batchSize <= 0 {
+		return nil, ErrInvalidBatchSize
+	}
+	var o cursorOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	query, values, err := b.db.build(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cursor{
+		db:      b.db,
+		builder: b,
+		release: func() {},
+		name:    "pgr_cursor_" + strconv.FormatUint(atomic.AddUint64(&cursorID, 1), 10),
+		size:    batchSize,
+	}
+	if tx := getTransaction(ctx); tx != nil && !o.hold {
+		c.conn = tx
+	} else if o.hold {
+		// the cursor outlives transactions, so it needs its own connection
+		c.conn, c.release, err = b.db.acquire(ctx)
+		if err != nil {
+			return nil, pgError(err)
+		}
+	} else {
+		c.tx, err = b.db.conn.Begin(ctx)
+		if err != nil {
+			return nil, pgError(err)
+		}
+		c.conn = c.tx
+	}
+
+	declare := "DECLARE " + QuoteIdent(c.name) + " NO SCROLL CURSOR "
+	if o.hold {
+		declare += "WITH HOLD "
+	}
+	err = c.exec(ctx, declare+"FOR "+query, values)
+	if err != nil {
+		if c.tx != nil {
+			c.tx.Rollback(ctx)
+		}
+		c.release()
+		return nil, err
+	}
+	return c, nil
+}
+
+// exec runs a statement of the cursor on its connection, with the hooks
+// and the statement timeout of the builder, like the builders do.
+func (c *Cursor) exec(ctx context.Context, query string, values []interface{}) error {
+	ctx, event, err := c.db.before(ctx, c.builder, query, values)
+	if err != nil {
+		return err
+	}
+	_, err = c.db.execOn(ctx, c.conn, c.builder, query, values)
+	err = pgError(c.db.wrapTimeout(ctx, c.builder, err))
+	event.Err = err
+	c.db.after(ctx, event)
+	return err
+}
+
+// query is like exec for a statement returning rows,
+// whose event completes when the rows are closed.
+func (c *Cursor) query(ctx context.Context, query string) (pgx.Rows, error) {
+	ctx, event, err := c.db.before(ctx, c.builder, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := c.db.queryOn(ctx, c.conn, c.builder, query, nil)
+	if err != nil {
+		err = pgError(c.db.wrapTimeout(ctx, c.builder, err))
+		event.Err = err
+		c.db.after(ctx, event)
+		return nil, err
+	}
+	return &eventRows{
+		Rows:  rows,
+		ctx:   ctx,
+		db:    c.db,
+		event: event,
+	}, nil
+}
+
+// Next fetches the next batch of records into dest, which is reset first.
+// It returns 0 once all records have been read.
+func (c *Cursor) Next(ctx context.Context, dest interface{}) (int, error) {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return 0, ErrInvalidPointer
+	}
+	v.Elem().Set(reflect.Zero(v.Elem().Type()))
+
+	rows, err := c.query(ctx, fmt.Sprintf("FETCH %d FROM %s", c.size, QuoteIdent(c.name)))
+	if err != nil {
+		return 0, err
+	}
+	count, err := Load(rows, dest)
+	return count, pgError(err)
+}
+
+// Close closes the cursor, and commits the transaction it began if any.
+func (c *Cursor) Close(ctx context.Context) error {
+	if c.tx != nil {
+		return pgError(c.tx.Commit(ctx))
+	}
+	defer c.release()
+	_, err := c.conn.Exec(ctx, "CLOSE "+QuoteIdent(c.name))
+	return pgError(err)
+}
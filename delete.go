@@ -2,17 +2,24 @@ package pgr
 
 import (
 	"context"
-	"strconv"
+	"time"
 )
 
 type DeleteBuilder struct {
 	db *Pgr
 	raw
 
+	timeout time.Duration
+
 	table        string
+	usingTables  []interface{}
 	whereCond    []Builder
+	order        []Builder
 	limitCount   int64
+	skipLocked   bool
 	returnColumn []string
+	allRows      bool
+	hardDelete   bool
 }
 
 func (db *Pgr) DeleteFrom(table string) *DeleteBuilder {
@@ -34,6 +41,14 @@ func (db *Pgr) DeleteSql(query string, value ...interface{}) *DeleteBuilder {
 	}
 }
 
+// Using adds a table to the USING clause, so that rows of other tables can be
+// used in the where condition.
+// table can be Builder or string.
+func (b *DeleteBuilder) Using(table interface{}) *DeleteBuilder {
+	b.usingTables = append(b.usingTables, table)
+	return b
+}
+
 func (b *DeleteBuilder) Where(query interface{}, value ...interface{}) *DeleteBuilder {
 	switch query := query.(type) {
 	case string:
@@ -49,6 +64,59 @@ func (b *DeleteBuilder) Returning(columns ...string) *DeleteBuilder {
 	return b
 }
 
+// OrderAsc adds a column to the ORDER BY clause used with Limit.
+func (b *DeleteBuilder) OrderAsc(col string) *DeleteBuilder {
+	b.order = append(b.order, order(col, asc))
+	return b
+}
+
+// OrderDesc adds a column to the ORDER BY clause used with Limit.
+func (b *DeleteBuilder) OrderDesc(col string) *DeleteBuilder {
+	b.order = append(b.order, order(col, desc))
+	return b
+}
+
+// OrderBy specifies columns for ordering the rows affected with Limit.
+func (b *DeleteBuilder) OrderBy(col string) *DeleteBuilder {
+	b.order = append(b.order, Expr(col))
+	return b
+}
+
+// Limit restricts the statement to the first n rows matching the where
+// condition. Since postgres has no LIMIT for this statement, the condition is
+// rewritten to `(tableoid, ctid) IN (SELECT tableoid, ctid ... LIMIT n)`.
+// It can't be used together with Using.
+func (b *DeleteBuilder) Limit(n uint64) *DeleteBuilder {
+	b.limitCount = int64(n)
+	return b
+}
+
+// SkipLocked locks the rows selected by Limit with FOR UPDATE SKIP LOCKED,
+// so that concurrent statements work on different rows instead of waiting.
+func (b *DeleteBuilder) SkipLocked() *DeleteBuilder {
+	b.skipLocked = true
+	return b
+}
+
+// limited reports whether the condition has to be rewritten to
+// honour Limit, OrderBy or SkipLocked.
+func (b *DeleteBuilder) limited() bool {
+	return b.limitCount >= 0 || len(b.order) > 0 || b.skipLocked
+}
+
+// HardDelete deletes the rows of a soft deleted table for real.
+func (b *DeleteBuilder) HardDelete() *DeleteBuilder {
+	b.hardDelete = true
+	return b
+}
+
+// AllRows allows the statement to run without a where condition,
+// affecting all rows of the table.
+func (b *DeleteBuilder) AllRows() *DeleteBuilder {
+	b.allRows = true
+	return b
+}
+
 func (b *DeleteBuilder) Exec(ctx context.Context) (int64, error) {
 	return b.db.exec(ctx, b)
 }
@@ -58,8 +126,26 @@ func (b *DeleteBuilder) Load(ctx context.Context, dest interface{}) error {
 	return err
 }
 
+// Timeout sets the statement timeout of the query, which is enforced by the
+// server and reported as ErrStatementTimeout.
+func (b *DeleteBuilder) Timeout(d time.Duration) *DeleteBuilder {
+	b.timeout = d
+	return b
+}
+
+func (b *DeleteBuilder) statementTimeout() time.Duration {
+	return b.timeout
+}
+
+func (b *DeleteBuilder) pgr() *Pgr {
+	return b.db
+}
+
 func (b *DeleteBuilder) Build(buf Buffer) error {
 	if b.raw.Query != "" {
+		if b.db != nil && b.db.requireWhere && !b.allRows && !hasWhere(b.raw.Query) {
+			return ErrMissingWhere
+		}
 		return b.raw.Build(buf)
 	}
 
@@ -67,22 +153,54 @@ func (b *DeleteBuilder) Build(buf Buffer) error {
 		return ErrTableNotSpecified
 	}
 
-	buf.WriteString("DELETE FROM ")
-	buf.WriteString(QuoteIdent(b.table))
+	if len(b.whereCond) == 0 && !b.allRows {
+		return ErrMissingWhere
+	}
+
+	var column string
+	if !b.hardDelete {
+		column = b.db.softDeleteColumn(b.table)
+	}
+
+	whereCond := b.whereCond
+	if column != "" {
+		whereCond = append(whereCond[:len(whereCond):len(whereCond)], deletedCond(b.table, column, false))
+	}
+	if b.limited() {
+		if len(b.usingTables) > 0 {
+			return ErrNotSupported
+		}
+		whereCond = []Builder{limitRows(b.table, whereCond, b.order, b.limitCount, b.skipLocked)}
+	}
+
+	if column != "" {
+		buf.WriteString("UPDATE ")
+		buf.WriteString(QuoteIdent(b.table))
+		buf.WriteString(" SET ")
+		buf.WriteString(QuoteIdent(column))
+		buf.WriteString(" = now()")
+	} else {
+		buf.WriteString("DELETE FROM ")
+		buf.WriteString(QuoteIdent(b.table))
+	}
+
+	if len(b.usingTables) > 0 {
+		if column != "" {
+			buf.WriteString(" FROM ")
+		} else {
+			buf.WriteString(" USING ")
+		}
+		buildTables(buf, b.usingTables)
+	}
 
-	if len(b.whereCond) > 0 {
+	if len(whereCond) > 0 {
 		buf.WriteString(" WHERE ")
-		err := And(b.whereCond...).Build(buf)
+		err := And(whereCond...).Build(buf)
 		if err != nil {
 			return err
 		}
 	}
 
-	if b.limitCount >= 0 {
-		buf.WriteString(" LIMIT ")
-		buf.WriteString(strconv.FormatInt(b.limitCount, 10))
-	}
-
 	if len(b.returnColumn) > 0 {
 		buf.WriteString(" RETURNING ")
 		for i, col := range b.returnColumn {
@@ -0,0 +1,98 @@
+package pgr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteBuilder(t *testing.T) {
+	db := getDb()
+
+	t.Run("delete using", func(t *testing.T) {
+		buf := NewBuffer()
+		err := db.DeleteFrom("user_movies").
+			Using("movies").
+			Where(And(Expr("movies.id = user_movies.movie_id"), Eq("movies.name", "a"))).
+			Returning("user_id").
+			Build(buf)
+		require.NoError(t, err)
+		require.Equal(t, `DELETE FROM "user_movies" USING movies `+
+			`WHERE ((movies.id = user_movies.movie_id) AND ("movies"."name" = ?)) `+
+			`RETURNING "user_id"`, buf.String())
+		require.Equal(t, []interface{}{"a"}, buf.Value())
+	})
+
+	t.Run("delete without where", func(t *testing.T) {
+		err := db.DeleteFrom("users").Build(NewBuffer())
+		require.ErrorIs(t, err, ErrMissingWhere)
+
+		buf := NewBuffer()
+		err = db.DeleteFrom("users").AllRows().Build(buf)
+		require.NoError(t, err)
+		require.Equal(t, `DELETE FROM "users"`, buf.String())
+	})
+
+	t.Run("raw delete without where", func(t *testing.T) {
+		strict, err := New(db.Conn(), &Config{RequireWhere: true})
+		require.NoError(t, err)
+
+		err = strict.DeleteSql("DELETE FROM users").Build(NewBuffer())
+		require.ErrorIs(t, err, ErrMissingWhere)
+
+		err = strict.DeleteSql("DELETE FROM users -- where id = 1").Build(NewBuffer())
+		require.ErrorIs(t, err, ErrMissingWhere)
+
+		err = strict.DeleteSql("WITH old AS (SELECT id FROM users WHERE age > 90) DELETE FROM users").Build(NewBuffer())
+		require.ErrorIs(t, err, ErrMissingWhere)
+
+		err = strict.DeleteSql("DELETE FROM users WHERE id IN (SELECT id FROM old)").Build(NewBuffer())
+		require.NoError(t, err)
+
+		err = strict.DeleteSql("DELETE FROM users").AllRows().Build(NewBuffer())
+		require.NoError(t, err)
+	})
+
+	t.Run("delete with limit", func(t *testing.T) {
+		buf := NewBuffer()
+		err := db.DeleteFrom("users").
+			Where(Lt("age", 18)).
+			OrderAsc("id").
+			Limit(100).
+			SkipLocked().
+			Returning("id").
+			Build(buf)
+		require.NoError(t, err)
+		require.Equal(t, `DELETE FROM "users" WHERE ((tableoid, ctid) IN (SELECT tableoid, ctid FROM "users" `+
+			`WHERE ("age" < ?) ORDER BY id ASC LIMIT 100 FOR UPDATE SKIP LOCKED)) `+
+			`RETURNING "id"`, buf.String())
+		require.Equal(t, []interface{}{18}, buf.Value())
+
+		err = db.DeleteFrom("users").Using("movies").Where("true").Limit(1).Build(NewBuffer())
+		require.ErrorIs(t, err, ErrNotSupported)
+	})
+
+	t.Run("soft delete", func(t *testing.T) {
+		db := getDb()
+		err := db.SoftDeleteModel("users", struct {
+			DeletedAt *string `db:"deleted_at,softdelete"`
+		}{})
+		require.NoError(t, err)
+
+		require.ErrorIs(t, db.SoftDeleteModel("users", nil), ErrInvalidPointer)
+		require.ErrorIs(t, db.SoftDeleteModel("users", (*User)(nil)), ErrInvalidPointer)
+		require.ErrorIs(t, db.SoftDeleteModel("users", 42), ErrNotSupported)
+		require.ErrorIs(t, db.SoftDeleteModel("users", User{}), ErrColumnNotSpecified)
+
+		buf := NewBuffer()
+		err = db.DeleteFrom("users").Where(Eq("id", 1)).Build(buf)
+		require.NoError(t, err)
+		require.Equal(t, `UPDATE "users" SET "deleted_at" = now() `+
+			`WHERE ("id" = ?) AND ("users"."deleted_at" IS NULL)`, buf.String())
+
+		buf = NewBuffer()
+		err = db.DeleteFrom("users").Where(Eq("id", 1)).HardDelete().Build(buf)
+		require.NoError(t, err)
+		require.Equal(t, `DELETE FROM "users" WHERE ("id" = ?)`, buf.String())
+	})
+}
@@ -1,6 +1,10 @@
 package pgr
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/jackc/pgconn"
+)
 
 var (
 	ErrNotConnection      = errors.New("pgr: connection is not set")
@@ -11,4 +15,89 @@ var (
 	ErrInvalidPointer     = errors.New("pgr: attempt to load into an invalid pointer")
 	ErrPlaceholderCount   = errors.New("pgr: wrong placeholder count")
 	ErrInvalidSliceLength = errors.New("pgr: length of slice is 0. length must be >= 1")
+	ErrPrimaryKeyNotFound = errors.New("pgr: primary key not found")
+	ErrMissingWhere       = errors.New("pgr: missing where condition. use AllRows to affect all rows")
+	ErrStatementTimeout   = errors.New("pgr: statement timeout")
+	ErrNotInTransaction   = errors.New("pgr: not in a transaction")
+	ErrInvalidBatchSize   = errors.New("pgr: batch size must be > 0")
+)
+
+// Errors returned by postgres, matched with errors.Is.
+// errors.As with a *PgError gives the constraint, table and column.
+var (
+	ErrUniqueViolation      = errors.New("pgr: unique violation")
+	ErrForeignKeyViolation  = errors.New("pgr: foreign key violation")
+	ErrNotNullViolation     = errors.New("pgr: not null violation")
+	ErrCheckViolation       = errors.New("pgr: check violation")
+	ErrExclusionViolation   = errors.New("pgr: exclusion violation")
+	ErrSerializationFailure = errors.New("pgr: serialization failure")
+	ErrDeadlock             = errors.New("pgr: deadlock detected")
+	ErrLockNotAvailable     = errors.New("pgr: lock not available")
+	ErrQueryCanceled        = errors.New("pgr: query canceled")
+	ErrUndefinedTable       = errors.New("pgr: undefined table")
 )
+
+var pgErrorCodes = map[string]error{
+	"23505": ErrUniqueViolation,
+	"23503": ErrForeignKeyViolation,
+	"23502": ErrNotNullViolation,
+	"23514": ErrCheckViolation,
+	"23P01": ErrExclusionViolation,
+	"40001": ErrSerializationFailure,
+	"40P01": ErrDeadlock,
+	"55P03": ErrLockNotAvailable,
+	"57014": ErrQueryCanceled,
+	"42P01": ErrUndefinedTable,
+}
+
+// PgError is an error reported by postgres.
+// It unwraps to the *pgconn.PgError returned by pgx.
+type PgError struct {
+	// Code is the SQLSTATE code of the error.
+	Code       string
+	Message    string
+	Detail     string
+	Schema     string
+	Table      string
+	Column     string
+	Constraint string
+
+	err error
+}
+
+func (e *PgError) Error() string {
+	return e.err.Error()
+}
+
+// Is reports whether target is the sentinel error of the code.
+func (e *PgError) Is(target error) bool {
+	sentinel, ok := pgErrorCodes[e.Code]
+	return ok && sentinel == target
+}
+
+func (e *PgError) Unwrap() error {
+	return e.err
+}
+
+// pgError wraps the errors reported by postgres into a *PgError,
+// and returns the other errors unchanged.
+func pgError(err error) error {
+	var pgErr *pgconn.PgError
+	if err == nil || !errors.As(err, &pgErr) {
+		return err
+	}
+	var wrapped *PgError
+	if errors.As(err, &wrapped) {
+		return err
+	}
+	return &PgError{
+		Code:       pgErr.Code,
+		Message:    pgErr.Message,
+		Detail:     pgErr.Detail,
+		Schema:     pgErr.SchemaName,
+		Table:      pgErr.TableName,
+		Column:     pgErr.ColumnName,
+		Constraint: pgErr.ConstraintName,
+		err:        err,
+	}
+}
@@ -0,0 +1,82 @@
+package pgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPgError(t *testing.T) {
+	t.Run("classify", func(t *testing.T) {
+		err := pgError(fmt.Errorf("insert: %w", &pgconn.PgError{
+			Code:           "23505",
+			Message:        "duplicate key value violates unique constraint",
+			TableName:      "users",
+			ConstraintName: "users_email_key",
+		}))
+		require.ErrorIs(t, err, ErrUniqueViolation)
+		require.NotErrorIs(t, err, ErrForeignKeyViolation)
+
+		var pgErr *PgError
+		require.ErrorAs(t, err, &pgErr)
+		require.Equal(t, "users", pgErr.Table)
+		require.Equal(t, "users_email_key", pgErr.Constraint)
+
+		var raw *pgconn.PgError
+		require.ErrorAs(t, err, &raw)
+		require.Equal(t, "23505", raw.Code)
+
+		require.Same(t, err, pgError(err))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		err := pgError(checkTimeout(context.Background(), &pgconn.PgError{Code: "57014"}))
+		require.ErrorIs(t, err, ErrStatementTimeout)
+		require.ErrorIs(t, err, ErrQueryCanceled)
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		err := pgError(&pgconn.PgError{Code: "40001"})
+		require.ErrorIs(t, err, ErrSerializationFailure)
+		require.True(t, RetryPolicy{}.retryable(err))
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		require.NoError(t, pgError(nil))
+		err := errors.New("other")
+		require.Same(t, err, pgError(err))
+	})
+}
+
+func TestPgErrorExec(t *testing.T) {
+	db := getDb()
+	ctx := context.Background()
+
+	_, err := db.Conn().Exec(ctx, `CREATE TEMP TABLE emails (email text CONSTRAINT emails_email_key UNIQUE NOT NULL)`)
+	require.NoError(t, err)
+	_, err = db.InsertInto("emails").Columns("email").Values("a@example.com").Exec(ctx)
+	require.NoError(t, err)
+
+	_, err = db.InsertInto("emails").Columns("email").Values("a@example.com").Exec(ctx)
+	require.ErrorIs(t, err, ErrUniqueViolation)
+	var pgErr *PgError
+	require.ErrorAs(t, err, &pgErr)
+	require.Equal(t, "emails", pgErr.Table)
+	require.Equal(t, "emails_email_key", pgErr.Constraint)
+
+	// the failed statement aborted the transaction of db
+	var id []int
+	_, err = getDb().Select("id").From("missing_table").Load(ctx, &id)
+	require.ErrorIs(t, err, ErrUndefinedTable)
+}
+
+func TestPgErrorNotify(t *testing.T) {
+	err := getDb().Notify(context.Background(), "", "payload")
+	var pgErr *PgError
+	require.ErrorAs(t, err, &pgErr)
+	require.Equal(t, "22023", pgErr.Code)
+}
@@ -0,0 +1,60 @@
+package pgr
+
+import "context"
+
+// Query is a builder bound to a Pgr: SelectBuilder, or InsertBuilder,
+// UpdateBuilder and DeleteBuilder with Returning.
+type Query interface {
+	Builder
+	pgr() *Pgr
+}
+
+func load(ctx context.Context, q Query, dest interface{}) (int, error) {
+	db := q.pgr()
+	if db == nil {
+		return 0, ErrNotConnection
+	}
+	return db.query(ctx, q, dest)
+}
+
+// All executes the query and returns all records.
+func All[T any](ctx context.Context, q Query) ([]T, error) {
+	dest := make([]T, 0)
+	_, err := load(ctx, q, &dest)
+	if err != nil {
+		return nil, err
+	}
+	return dest, nil
+}
+
+// One executes the query and returns the first record.
+// It returns ErrNotFound if there is none.
+func One[T any](ctx context.Context, q Query) (T, error) {
+	var dest T
+	count, err := load(ctx, q, &dest)
+	if err != nil {
+		return dest, err
+	}
+	if count == 0 {
+		return dest, ErrNotFound
+	}
+	return dest, nil
+}
+
+// Scalar executes the query and returns the single column of the first row,
+// like `SELECT count(*) ...`.
+// It returns ErrNotFound if there is no row.
+func Scalar[T any](ctx context.Context, q Query) (T, error) {
+	return One[T](ctx, q)
+}
+
+// Map executes the query and returns the records keyed by the first column.
+// The rest of the columns are loaded into the values.
+func Map[K comparable, V any](ctx context.Context, q Query) (map[K]V, error) {
+	dest := make(map[K]V)
+	_, err := load(ctx, q, &dest)
+	if err != nil {
+		return nil, err
+	}
+	return dest, nil
+}
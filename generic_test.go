@@ -0,0 +1,41 @@
+package pgr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneric(t *testing.T) {
+	db := getDb()
+	ctx := context.Background()
+
+	ids, err := All[int64](ctx, db.InsertInto("users").
+		Columns("name", "age").
+		Values("a", 1).
+		Values("b", 2).
+		Returning("id"))
+	require.NoError(t, err)
+	require.Equal(t, 2, len(ids))
+
+	users, err := All[User](ctx, db.Select("id", "name", "age").From("users").OrderAsc("id"))
+	require.NoError(t, err)
+	require.Equal(t, 2, len(users))
+	require.Equal(t, "a", users[0].Name)
+
+	user, err := One[User](ctx, db.Select("id", "name", "age").From("users").Where(Eq("id", ids[1])))
+	require.NoError(t, err)
+	require.Equal(t, "b", user.Name)
+
+	_, err = One[User](ctx, db.Select("id").From("users").Where(Eq("id", -1)))
+	require.ErrorIs(t, err, ErrNotFound)
+
+	count, err := Scalar[int](ctx, db.Select("COUNT(*)").From("users"))
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	names, err := Map[int64, string](ctx, db.Select("id", "name").From("users"))
+	require.NoError(t, err)
+	require.Equal(t, map[int64]string{ids[0]: "a", ids[1]: "b"}, names)
+}
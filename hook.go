@@ -0,0 +1,104 @@
+package pgr
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// QueryEvent describes the execution of the statement of a builder.
+type QueryEvent struct {
+	// Builder is the builder of the statement.
+	Builder Builder
+	// SQL and Args are the statement sent to the database.
+	SQL  string
+	Args []interface{}
+	// Fingerprint is the normalized SQL, see Fingerprint.
+	Fingerprint string
+
+	// Start is the time the statement started.
+	Start time.Time
+	// Duration, RowsAffected and Err are set once the statement completed.
+	// For queries, RowsAffected is the number of rows returned.
+	Duration     time.Duration
+	RowsAffected int64
+	Err          error
+}
+
+// Hook is called around the execution of the statements of builders.
+// Hooks are registered in the Config.
+type Hook interface {
+	// BeforeQuery is called before the statement is sent. The returned
+	// context is used to run the statement and is given to AfterQuery.
+	// Statements of a Batch are sent together with the context given to
+	// Send instead, so for them the returned context only reaches AfterQuery.
+	// Returning an error aborts the statement.
+	BeforeQuery(ctx context.Context, event *QueryEvent) (context.Context, error)
+	// AfterQuery is called once the statement completed. For queries,
+	// it is called when the rows are closed.
+	AfterQuery(ctx context.Context, event *QueryEvent)
+}
+
+// before calls BeforeQuery of the hooks. If one of them aborts the statement,
+// AfterQuery is called on the hooks called before it.
+func (p *Pgr) before(ctx context.Context, builder Builder, query string, values []interface{}) (context.Context, *QueryEvent, error) {
+	event := &QueryEvent{
+		Builder:     builder,
+		SQL:         query,
+		Args:        values,
+		Fingerprint: Fingerprint(query),
+		Start:       time.Now(),
+	}
+	for i, hook := range p.hooks {
+		next, err := hook.BeforeQuery(ctx, event)
+		if err != nil {
+			event.Err = err
+			p.afterHooks(ctx, event, p.hooks[:i])
+			return ctx, nil, err
+		}
+		ctx = next
+	}
+	return ctx, event, nil
+}
+
+// after completes the event, records its metrics, logs it and calls
+// AfterQuery of the hooks, in reverse order.
+func (p *Pgr) after(ctx context.Context, event *QueryEvent) {
+	event.Duration = time.Since(event.Start)
+	p.metrics.observe(ctx, event)
+	p.logQuery(ctx, event)
+	p.afterHooks(ctx, event, p.hooks)
+}
+
+func (p *Pgr) afterHooks(ctx context.Context, event *QueryEvent, hooks []Hook) {
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i].AfterQuery(ctx, event)
+	}
+}
+
+// eventRows completes the event of a query when its rows are closed.
+type eventRows struct {
+	pgx.Rows
+	ctx    context.Context
+	db     *Pgr
+	event  *QueryEvent
+	closed bool
+}
+
+func (r *eventRows) Close() {
+	if r.closed {
+		return
+	}
+	r.closed = true
+	r.Rows.Close()
+	r.event.RowsAffected = r.Rows.CommandTag().RowsAffected()
+	r.event.Err = r.Err()
+	r.db.after(r.ctx, r.event)
+}
+
+// Err returns the error of the rows, typed as a *PgError when it was
+// reported by postgres.
+func (r *eventRows) Err() error {
+	return pgError(r.db.wrapTimeout(r.ctx, r.event.Builder, r.Rows.Err()))
+}
@@ -0,0 +1,46 @@
+package pgr
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingHook struct {
+	name   string
+	calls  *[]string
+	abort  error
+	events []*QueryEvent
+}
+
+func (h *recordingHook) BeforeQuery(ctx context.Context, event *QueryEvent) (context.Context, error) {
+	*h.calls = append(*h.calls, "before "+h.name)
+	return ctx, h.abort
+}
+
+func (h *recordingHook) AfterQuery(ctx context.Context, event *QueryEvent) {
+	*h.calls = append(*h.calls, "after "+h.name)
+	h.events = append(h.events, event)
+}
+
+func TestHooks(t *testing.T) {
+	var calls []string
+	denied := errors.New("denied")
+	first := &recordingHook{name: "first", calls: &calls}
+	second := &recordingHook{name: "second", calls: &calls, abort: denied}
+	third := &recordingHook{name: "third", calls: &calls}
+
+	db, err := New(nil, &Config{Hooks: []Hook{first, second, third}})
+	require.NoError(t, err)
+
+	_, err = db.DeleteFrom("users").Where(Eq("id", 1)).Exec(context.Background())
+	require.ErrorIs(t, err, denied)
+	require.Equal(t, []string{"before first", "before second", "after first"}, calls)
+
+	event := first.events[0]
+	require.Equal(t, `DELETE FROM "users" WHERE ("id" = 1)`, event.SQL)
+	require.ErrorIs(t, event.Err, denied)
+	require.IsType(t, &DeleteBuilder{}, event.Builder)
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"reflect"
 	"strings"
+	"time"
 )
 
 type InsertBuilder struct {
 	db *Pgr
 	raw
 
+	timeout time.Duration
+
 	table        string
 	columns      []string
 	returnColumn []string
@@ -117,6 +120,21 @@ func (b *InsertBuilder) Load(ctx context.Context, dest interface{}) error {
 	return err
 }
 
+// Timeout sets the statement timeout of the query, which is enforced by the
+// server and reported as ErrStatementTimeout.
+func (b *InsertBuilder) Timeout(d time.Duration) *InsertBuilder {
+	b.timeout = d
+	return b
+}
+
+func (b *InsertBuilder) statementTimeout() time.Duration {
+	return b.timeout
+}
+
+func (b *InsertBuilder) pgr() *Pgr {
+	return b.db
+}
+
 func (b *InsertBuilder) Build(buf Buffer) error {
 	if b.raw.Query != "" {
 		return b.raw.Build(buf)
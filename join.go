@@ -39,3 +39,22 @@ func join(t joinType, table interface{}, on interface{}) Builder {
 		return nil
 	})
 }
+
+func buildTable(buf Buffer, table interface{}) {
+	switch table := table.(type) {
+	case string:
+		buf.WriteString(table)
+	default:
+		buf.WriteString(placeholder)
+		buf.WriteValue(table)
+	}
+}
+
+func buildTables(buf Buffer, tables []interface{}) {
+	for i, table := range tables {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buildTable(buf, table)
+	}
+}
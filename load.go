@@ -136,6 +136,59 @@ func Load(rows pgx.Rows, value interface{}) (int, error) {
 	return count, rows.Err()
 }
 
+// Each loads rows one at a time into value and calls fn after each row,
+// without holding the whole result set in memory.
+//
+// value is reset to its zero value before each row. Each stops at the first
+// error returned by fn and returns it. rows are always closed.
+func Each(rows pgx.Rows, value interface{}, fn func() error) (int, error) {
+	defer rows.Close()
+
+	column, err := getColumns(rows)
+	if err != nil {
+		return 0, err
+	}
+	ptr := make([]interface{}, len(column))
+
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return 0, ErrInvalidPointer
+	}
+	v = v.Elem()
+	zero := reflect.Zero(v.Type())
+
+	s := newTagStore()
+	count := 0
+	for rows.Next() {
+		v.Set(zero)
+		err := s.findPtr(v, column, ptr)
+		if err != nil {
+			return count, err
+		}
+
+		for i := range ptr {
+			if ptr[i] == nil {
+				ptr[i] = dummyDest
+			}
+		}
+		err = rows.Scan(ptr...)
+		if err != nil {
+			return count, err
+		}
+		for i := range ptr {
+			ptr[i] = nil
+		}
+
+		count++
+
+		err = fn()
+		if err != nil {
+			return count, err
+		}
+	}
+	return count, rows.Err()
+}
+
 func reflectAlloc(typ reflect.Type) reflect.Value {
 	if typ.Kind() == reflect.Ptr {
 		return reflect.New(typ.Elem())
@@ -1,8 +1,14 @@
 package pgr
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
 )
 
 type LogLevel int
@@ -32,18 +38,118 @@ func (ll LogLevel) String() string {
 	}
 }
 
-type kvs map[string]string
+// Field is a structured value attached to a log entry.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// StringField returns a string log field.
+func StringField(key, value string) Field {
+	return Field{key, value}
+}
+
+// Int64Field returns an integer log field.
+func Int64Field(key string, value int64) Field {
+	return Field{key, value}
+}
+
+// DurationField returns a duration log field.
+func DurationField(key string, value time.Duration) Field {
+	return Field{key, value}
+}
 
+// ErrorField returns a log field with the key "error".
+func ErrorField(err error) Field {
+	return Field{"error", err}
+}
+
+// AnyField returns a log field of any value.
+func AnyField(key string, value interface{}) Field {
+	return Field{key, value}
+}
+
+// Logger receives the logs of pgr. The context is the one of the
+// operation being logged, so that request scoped values can be attached.
 type Logger interface {
-	Log(level LogLevel, data kvs)
+	Log(ctx context.Context, level LogLevel, msg string, fields ...Field)
 }
 
-type defLogger struct {
-	level LogLevel
+// LoggerFunc adapts a function to a Logger.
+type LoggerFunc func(ctx context.Context, level LogLevel, msg string, fields ...Field)
+
+// Log calls f.
+func (f LoggerFunc) Log(ctx context.Context, level LogLevel, msg string, fields ...Field) {
+	f(ctx, level, msg, fields...)
+}
+
+// NewStdLogger returns a Logger printing to l the entries enabled by level.
+// LogLevelNone disables all logs.
+func NewStdLogger(l *log.Logger, level LogLevel) Logger {
+	return &stdLogger{
+		logger: l,
+		level:  level,
+	}
 }
 
-func (l *defLogger) Log(level LogLevel, data kvs) {
-	if level >= l.level {
-		log.Printf("[%s] %v", level.String(), data)
+type stdLogger struct {
+	logger *log.Logger
+	level  LogLevel
+}
+
+func (l *stdLogger) Log(ctx context.Context, level LogLevel, msg string, fields ...Field) {
+	if level == LogLevelNone || level > l.level {
+		return
+	}
+	var b strings.Builder
+	b.WriteString("[")
+	b.WriteString(level.String())
+	b.WriteString("] ")
+	b.WriteString(msg)
+	for _, f := range fields {
+		fmt.Fprintf(&b, " %s=%v", f.Key, f.Value)
+	}
+	l.logger.Print(b.String())
+}
+
+// logQuery logs a completed statement: at warn level with its caller if it
+// is slower than the threshold, and at debug level otherwise.
+func (p *Pgr) logQuery(ctx context.Context, event *QueryEvent) {
+	fields := []Field{
+		StringField("sql", event.SQL),
+		AnyField("args", event.Args),
+		DurationField("duration", event.Duration),
+		Int64Field("rows", event.RowsAffected),
+	}
+	if event.Err != nil {
+		fields = append(fields, ErrorField(event.Err))
+	}
+
+	if p.slowQuery > 0 && event.Duration >= p.slowQuery {
+		fields = append(fields, StringField("caller", caller()))
+		p.logger.Log(ctx, LogLevelWarn, "slow query", fields...)
+		return
+	}
+	if p.logSampleRate > 0 && p.logSampleRate < 1 && rand.Float64() >= p.logSampleRate {
+		return
+	}
+	p.logger.Log(ctx, LogLevelDebug, "query", fields...)
+}
+
+var pkgPrefix = reflect.TypeOf(Pgr{}).PkgPath() + "."
+
+// caller returns the file and line of the first caller outside of pgr.
+func caller() string {
+	pc := make([]uintptr, 32)
+	n := runtime.Callers(2, pc)
+	frames := runtime.CallersFrames(pc[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, pkgPrefix) || strings.HasSuffix(frame.File, "_test.go") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
 	}
 }
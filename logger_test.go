@@ -0,0 +1,112 @@
+package pgr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type logEntry struct {
+	level  LogLevel
+	msg    string
+	fields map[string]interface{}
+}
+
+type recordingLogger struct {
+	entries []logEntry
+}
+
+func (l *recordingLogger) Log(ctx context.Context, level LogLevel, msg string, fields ...Field) {
+	entry := logEntry{level, msg, make(map[string]interface{})}
+	for _, f := range fields {
+		entry.fields[f.Key] = f.Value
+	}
+	l.entries = append(l.entries, entry)
+}
+
+func TestStdLogger(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewStdLogger(log.New(&out, "", 0), LogLevelWarn)
+	ctx := context.Background()
+
+	logger.Log(ctx, LogLevelDebug, "query", StringField("sql", "SELECT 1"))
+	require.Empty(t, out.String())
+
+	logger.Log(ctx, LogLevelWarn, "slow query", StringField("sql", "SELECT 1"), DurationField("duration", time.Second), Int64Field("rows", 2))
+	require.Equal(t, "[warn] slow query sql=SELECT 1 duration=1s rows=2\n", out.String())
+
+	out.Reset()
+	NewStdLogger(log.New(&out, "", 0), LogLevelNone).Log(ctx, LogLevelError, "failed", ErrorField(errors.New("boom")))
+	require.Empty(t, out.String())
+}
+
+func TestLoggerFunc(t *testing.T) {
+	type key struct{}
+	var requestID interface{}
+	logger := LoggerFunc(func(ctx context.Context, level LogLevel, msg string, fields ...Field) {
+		requestID = ctx.Value(key{})
+	})
+	db, err := New(nil, &Config{Logger: logger})
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), key{}, "req-1")
+	db.after(ctx, &QueryEvent{Builder: Expr("SELECT 1"), SQL: "SELECT 1", Start: time.Now()})
+	require.Equal(t, "req-1", requestID)
+}
+
+func TestLogQuery(t *testing.T) {
+	ctx := context.Background()
+	event := func(d time.Duration) *QueryEvent {
+		return &QueryEvent{
+			Builder: Expr("SELECT 1"),
+			SQL:     "SELECT 1",
+			Start:   time.Now().Add(-d),
+		}
+	}
+
+	t.Run("debug", func(t *testing.T) {
+		logger := &recordingLogger{}
+		db, err := New(nil, &Config{Logger: logger})
+		require.NoError(t, err)
+
+		db.after(ctx, event(0))
+		require.Len(t, logger.entries, 1)
+		require.Equal(t, LogLevelDebug, logger.entries[0].level)
+		require.Equal(t, "SELECT 1", logger.entries[0].fields["sql"])
+		require.IsType(t, time.Duration(0), logger.entries[0].fields["duration"])
+	})
+
+	t.Run("slow", func(t *testing.T) {
+		logger := &recordingLogger{}
+		db, err := New(nil, &Config{Logger: logger, SlowQueryThreshold: time.Second})
+		require.NoError(t, err)
+
+		db.after(ctx, event(2*time.Second))
+		require.Len(t, logger.entries, 1)
+		require.Equal(t, LogLevelWarn, logger.entries[0].level)
+		require.Equal(t, "slow query", logger.entries[0].msg)
+		require.Contains(t, logger.entries[0].fields["caller"], "logger_test.go:")
+	})
+
+	t.Run("sampling", func(t *testing.T) {
+		logger := &recordingLogger{}
+		db, err := New(nil, &Config{
+			Logger:             logger,
+			SlowQueryThreshold: time.Second,
+			LogSampleRate:      1e-12,
+		})
+		require.NoError(t, err)
+
+		for i := 0; i < 100; i++ {
+			db.after(ctx, event(0))
+		}
+		require.Empty(t, logger.entries)
+		db.after(ctx, event(2*time.Second))
+		require.Len(t, logger.entries, 1)
+	})
+}
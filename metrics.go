@@ -0,0 +1,155 @@
+package pgr
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds of the latency histogram of QueryStats.
+var LatencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+// maxFingerprints bounds the number of fingerprints tracked by Stats.
+// The statements of further fingerprints are counted in Stats.OtherQueries.
+const maxFingerprints = 1000
+
+// QueryMetric is the measure of a statement given to a MetricsSink.
+type QueryMetric struct {
+	Fingerprint string
+	Operation   string
+	Table       string
+	Duration    time.Duration
+	// Rows is the number of rows returned or affected.
+	Rows int64
+	Err  error
+}
+
+// MetricsSink receives the measure of each statement, to export it to a
+// metrics backend. It is registered in the Config.
+type MetricsSink interface {
+	ObserveQuery(ctx context.Context, m QueryMetric)
+}
+
+// StatementKey groups statements by operation and table.
+type StatementKey struct {
+	Operation string
+	Table     string
+}
+
+// QueryStats aggregates the statements sharing a fingerprint,
+// or an operation and table.
+type QueryStats struct {
+	Count         int64
+	Errors        int64
+	Rows          int64
+	TotalDuration time.Duration
+	MaxDuration   time.Duration
+	// Latency is the histogram of durations: Latency[i] counts the
+	// statements which took at most LatencyBuckets[i], and the last
+	// element counts the slower ones.
+	Latency []int64
+}
+
+func (s *QueryStats) observe(m QueryMetric) {
+	if s.Latency == nil {
+		s.Latency = make([]int64, len(LatencyBuckets)+1)
+	}
+	s.Count++
+	if m.Err != nil {
+		s.Errors++
+	}
+	s.Rows += m.Rows
+	s.TotalDuration += m.Duration
+	if m.Duration > s.MaxDuration {
+		s.MaxDuration = m.Duration
+	}
+	i := 0
+	for i < len(LatencyBuckets) && m.Duration > LatencyBuckets[i] {
+		i++
+	}
+	s.Latency[i]++
+}
+
+func (s *QueryStats) clone() QueryStats {
+	c := *s
+	c.Latency = append([]int64(nil), s.Latency...)
+	return c
+}
+
+type metrics struct {
+	mu            sync.Mutex
+	byFingerprint map[string]*QueryStats
+	byStatement   map[StatementKey]*QueryStats
+	other         QueryStats
+	sink          MetricsSink
+}
+
+func newMetrics(sink MetricsSink) *metrics {
+	return &metrics{
+		byFingerprint: make(map[string]*QueryStats),
+		byStatement:   make(map[StatementKey]*QueryStats),
+		sink:          sink,
+	}
+}
+
+// observe records the statement of a completed event.
+func (m *metrics) observe(ctx context.Context, event *QueryEvent) {
+	op, table := statementInfo(event.Builder)
+	metric := QueryMetric{
+		Fingerprint: event.Fingerprint,
+		Operation:   op,
+		Table:       table,
+		Duration:    event.Duration,
+		Rows:        event.RowsAffected,
+		Err:         event.Err,
+	}
+
+	m.mu.Lock()
+	s, ok := m.byFingerprint[metric.Fingerprint]
+	if !ok {
+		if len(m.byFingerprint) < maxFingerprints {
+			s = &QueryStats{}
+			m.byFingerprint[metric.Fingerprint] = s
+		} else {
+			s = &m.other
+		}
+	}
+	s.observe(metric)
+	key := StatementKey{op, table}
+	s, ok = m.byStatement[key]
+	if !ok {
+		s = &QueryStats{}
+		m.byStatement[key] = s
+	}
+	s.observe(metric)
+	m.mu.Unlock()
+
+	if m.sink != nil {
+		m.sink.ObserveQuery(ctx, metric)
+	}
+}
+
+func (m *metrics) stats(stats *Stats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats.Queries = make(map[string]QueryStats, len(m.byFingerprint))
+	for k, s := range m.byFingerprint {
+		stats.Queries[k] = s.clone()
+	}
+	stats.OtherQueries = m.other.clone()
+	stats.Statements = make(map[StatementKey]QueryStats, len(m.byStatement))
+	for k, s := range m.byStatement {
+		stats.Statements[k] = s.clone()
+	}
+}
@@ -0,0 +1,108 @@
+package pgr
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSink struct {
+	metrics []QueryMetric
+}
+
+func (s *recordingSink) ObserveQuery(ctx context.Context, m QueryMetric) {
+	s.metrics = append(s.metrics, m)
+}
+
+func TestMetrics(t *testing.T) {
+	sink := &recordingSink{}
+	db, err := New(nil, &Config{MetricsSink: sink})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	for i, d := range []time.Duration{time.Millisecond / 2, 20 * time.Millisecond, 10 * time.Second} {
+		var err error
+		if i == 2 {
+			err = errors.New("failed")
+		}
+		sql := `SELECT * FROM users u WHERE ("id" = ` + strconv.Itoa(i) + `)`
+		db.metrics.observe(ctx, &QueryEvent{
+			Builder:      db.Select("*").From("users u").Where(Eq("id", i)),
+			SQL:          sql,
+			Fingerprint:  Fingerprint(sql),
+			Duration:     d,
+			RowsAffected: 2,
+			Err:          err,
+		})
+	}
+	db.metrics.observe(ctx, &QueryEvent{
+		Builder:      db.DeleteFrom("users").Where(Eq("id", 1)),
+		SQL:          `DELETE FROM "users" WHERE ("id" = 1)`,
+		Fingerprint:  `DELETE FROM "users" WHERE ("id" = ?)`,
+		Duration:     time.Millisecond,
+		RowsAffected: 1,
+	})
+
+	require.Len(t, sink.metrics, 4)
+	require.Equal(t, "delete", sink.metrics[3].Operation)
+	require.Equal(t, "users", sink.metrics[3].Table)
+
+	stats := db.Stats()
+	require.Nil(t, stats.Pool)
+	require.Len(t, stats.Queries, 2)
+
+	s := stats.Queries[`SELECT * FROM users u WHERE ("id" = ?)`]
+	require.Equal(t, int64(3), s.Count)
+	require.Equal(t, int64(1), s.Errors)
+	require.Equal(t, int64(6), s.Rows)
+	require.Equal(t, 10*time.Second, s.MaxDuration)
+	require.Equal(t, int64(1), s.Latency[0])
+	require.Equal(t, int64(1), s.Latency[3])
+	require.Equal(t, int64(1), s.Latency[len(LatencyBuckets)])
+
+	s = stats.Statements[StatementKey{"delete", "users"}]
+	require.Equal(t, int64(1), s.Count)
+	require.Equal(t, int64(1), s.Latency[0])
+}
+
+func TestMetricsOverflow(t *testing.T) {
+	db, err := New(nil, nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	for i := 0; i <= maxFingerprints; i++ {
+		db.metrics.observe(ctx, &QueryEvent{
+			Builder:     db.Select("*").From("users"),
+			Fingerprint: "SELECT " + strconv.Itoa(i),
+		})
+	}
+
+	stats := db.Stats()
+	require.Len(t, stats.Queries, maxFingerprints)
+	require.Equal(t, int64(1), stats.OtherQueries.Count)
+	require.Equal(t, int64(maxFingerprints+1), stats.Statements[StatementKey{"select", "users"}].Count)
+}
+
+func TestStats(t *testing.T) {
+	db, err := New(getDb().Conn(), nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = db.InsertInto("users").Columns("name", "age").Values("stats", 30).Exec(ctx)
+	require.NoError(t, err)
+	var users []User
+	_, err = db.Select("*").From("users").Where(Eq("name", "stats")).Load(ctx, &users)
+	require.NoError(t, err)
+
+	stats := db.Stats()
+	insert := stats.Statements[StatementKey{"insert", "users"}]
+	require.Equal(t, int64(1), insert.Count)
+	require.Equal(t, int64(1), insert.Rows)
+	query := stats.Queries[`SELECT * FROM users WHERE ("name" = ?)`]
+	require.Equal(t, int64(1), query.Count)
+	require.Equal(t, int64(len(users)), query.Rows)
+}
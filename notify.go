@@ -0,0 +1,173 @@
+package pgr
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+const (
+	listenBufferSize      = 64
+	listenRetryInterval   = time.Second
+	listenMaxRetryBackoff = 30 * time.Second
+)
+
+// Notification is a notification received by a Subscription.
+type Notification struct {
+	Channel string
+	Payload string
+	PID     uint32
+}
+
+// Decode unmarshals the JSON payload of the notification into v.
+func (n *Notification) Decode(v interface{}) error {
+	return json.Unmarshal([]byte(n.Payload), v)
+}
+
+// Subscription delivers the notifications of the channels it listens to.
+type Subscription struct {
+	// C receives the notifications. It is closed when the subscription ends.
+	C <-chan *Notification
+
+	db       *Pgr
+	channels []string
+	c        chan *Notification
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// Listen subscribes to the channels on a dedicated connection, until ctx is
+// done or the subscription is closed.
+//
+// When the connection is lost, the subscription reconnects and listens again.
+// Notifications sent while it is disconnected are lost.
+func (db *Pgr) Listen(ctx context.Context, channels ...string) (*Subscription, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &Subscription{
+		db:       db,
+		channels: channels,
+		c:        make(chan *Notification, listenBufferSize),
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	s.C = s.c
+
+	conn, err := s.connect(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	go s.run(ctx, conn)
+	return s, nil
+}
+
+// Close ends the subscription and closes its connection.
+func (s *Subscription) Close() {
+	s.cancel()
+	<-s.done
+}
+
+func (s *Subscription) connect(ctx context.Context) (*pgx.Conn, error) {
+	config, err := s.db.connConfig()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := pgx.ConnectConfig(ctx, config)
+	if err != nil {
+		return nil, pgError(err)
+	}
+	for _, channel := range s.channels {
+		_, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize())
+		if err != nil {
+			conn.Close(context.Background())
+			return nil, pgError(err)
+		}
+	}
+	return conn, nil
+}
+
+// reconnect connects again with an exponential backoff, until ctx is done.
+func (s *Subscription) reconnect(ctx context.Context) *pgx.Conn {
+	backoff := listenRetryInterval
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		conn, err := s.connect(ctx)
+		if err == nil {
+			return conn
+		}
+		s.db.logger.Log(ctx, LogLevelError, "listen reconnect failed", ErrorField(err))
+		backoff *= 2
+		if backoff > listenMaxRetryBackoff {
+			backoff = listenMaxRetryBackoff
+		}
+	}
+}
+
+func (s *Subscription) run(ctx context.Context, conn *pgx.Conn) {
+	defer close(s.done)
+	defer close(s.c)
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			conn.Close(context.Background())
+			if ctx.Err() != nil {
+				return
+			}
+			s.db.logger.Log(ctx, LogLevelError, "listen connection lost", ErrorField(err))
+			conn = s.reconnect(ctx)
+			if conn == nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case s.c <- &Notification{Channel: n.Channel, Payload: n.Payload, PID: n.PID}:
+		case <-ctx.Done():
+			conn.Close(context.Background())
+			return
+		}
+	}
+}
+
+// connConfig returns the configuration to open dedicated connections.
+func (p *Pgr) connConfig() (*pgx.ConnConfig, error) {
+	switch conn := p.conn.(type) {
+	case *pgxpool.Pool:
+		return conn.Config().ConnConfig, nil
+	case *pgx.Conn:
+		return conn.Config(), nil
+	}
+	return nil, ErrNotSupported
+}
+
+// Notify sends a notification on channel. A string or []byte payload is
+// sent as is, any other value is encoded to JSON.
+//
+// Inside a transaction, the notification is delivered when it commits,
+// and dropped if it rolls back.
+func (db *Pgr) Notify(ctx context.Context, channel string, payload interface{}) error {
+	var s string
+	switch payload := payload.(type) {
+	case string:
+		s = payload
+	case []byte:
+		s = string(payload)
+	default:
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		s = string(b)
+	}
+	_, err := db.connFor(ctx).Exec(ctx, "SELECT pg_notify($1, $2)", channel, s)
+	return pgError(err)
+}
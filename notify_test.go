@@ -0,0 +1,38 @@
+package pgr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListen(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open(ctx, DB_URL, nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	sub, err := db.Listen(ctx, "events")
+	require.NoError(t, err)
+	defer sub.Close()
+
+	type event struct {
+		Name string `json:"name"`
+	}
+	err = db.Transaction(ctx, func(ctx context.Context) error {
+		return db.Notify(ctx, "events", event{Name: "created"})
+	})
+	require.NoError(t, err)
+
+	select {
+	case n := <-sub.C:
+		require.Equal(t, "events", n.Channel)
+		var e event
+		require.NoError(t, n.Decode(&e))
+		require.Equal(t, "created", e.Name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification not received")
+	}
+}
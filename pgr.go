@@ -2,10 +2,12 @@ package pgr
 
 import (
 	"context"
-	"fmt"
+	"log"
+	"time"
 
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type DML interface {
@@ -20,13 +22,13 @@ type DML interface {
 	// With(name string, builder Builder) DML
 }
 
-// Conn returns the underlying pgx.Conn.
+// Conn is the connection used by Pgr.
+// It is implemented by *pgx.Conn, *pgxpool.Pool, *pgxpool.Conn and pgx.Tx.
 type Conn interface {
 	Begin(ctx context.Context) (pgx.Tx, error)
 	BeginFunc(ctx context.Context, f func(pgx.Tx) error) (err error)
 	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
 	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
-	Prepare(ctx context.Context, name, sql string) (*pgconn.StatementDescription, error)
 	Exec(ctx context.Context, sql string, arguments ...interface{}) (commandTag pgconn.CommandTag, err error)
 	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
 	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
@@ -34,13 +36,76 @@ type Conn interface {
 }
 
 type Pgr struct {
-	conn   Conn
-	logger Logger
+	conn           Conn
+	logger         Logger
+	requireWhere   bool
+	softDeletes    map[string]string
+	defaultTimeout time.Duration
+	retry          RetryPolicy
+	replicas       *replicaSet
+	replicaWait    time.Duration
+	hooks          []Hook
+	tracer         Tracer
+	metrics        *metrics
+	slowQuery      time.Duration
+	logSampleRate  float64
 }
 
 type Config struct {
-	Logger   Logger
+	Logger Logger
+	// LogLevel is the most verbose level printed by the default logger.
+	// The zero value, LogLevelNone, prints nothing.
 	LogLevel LogLevel
+	// SlowQueryThreshold logs the statements taking at least this long at
+	// warn level, with the file and line of their caller. Zero disables it.
+	SlowQueryThreshold time.Duration
+	// LogSampleRate is the fraction of statements logged at debug level,
+	// between 0 and 1. Zero logs all of them. Slow statements are always logged.
+	LogSampleRate float64
+
+	// RequireWhere makes UpdateSql and DeleteSql fail with ErrMissingWhere
+	// when the query has no WHERE clause, like Update and DeleteFrom do.
+	RequireWhere bool
+
+	// DefaultStatementTimeout is the statement timeout of the queries which
+	// don't set their own with Timeout. Zero means no timeout.
+	DefaultStatementTimeout time.Duration
+
+	// TransactionRetry is the policy used by Transaction to retry
+	// serialization failures and deadlocks. By default there are no retries.
+	TransactionRetry RetryPolicy
+
+	// Settings of the connection pool created by Open.
+	// Zero values keep the defaults of pgxpool.
+	MaxConns          int32
+	MinConns          int32
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+
+	// Replicas are read replicas of the connection. Selects run outside of
+	// transactions and not marked with Primary are sent to them, and fall
+	// back to the primary connection when no replica is healthy.
+	Replicas []Conn
+	// ReplicaSelection is the strategy used to choose among the replicas.
+	ReplicaSelection ReplicaSelection
+	// ReplicaRetryInterval is how long a replica whose connection failed
+	// is skipped. It defaults to 5 seconds.
+	ReplicaRetryInterval time.Duration
+	// ReplicaWaitTimeout is how long a read with a consistency token waits
+	// for the replica to replay it, before falling back to the primary.
+	// Zero falls back immediately. See WithConsistency.
+	ReplicaWaitTimeout time.Duration
+
+	// Hooks are called around the execution of the statements of builders.
+	Hooks []Hook
+
+	// Tracer starts a span for each statement, transaction, batch and copy.
+	Tracer Tracer
+
+	// MetricsSink receives the measure of each statement.
+	// The statistics are also aggregated in Stats.
+	MetricsSink MetricsSink
 }
 
 // creates a new Pgr instance
@@ -49,22 +114,42 @@ func New(conn Conn, conf *Config) (*Pgr, error) {
 		conf = &Config{}
 	}
 	if conf.Logger == nil {
-		conf.Logger = &defLogger{
-			level: conf.LogLevel,
-		}
+		conf.Logger = NewStdLogger(log.Default(), conf.LogLevel)
+	}
+	hooks := conf.Hooks
+	if conf.Tracer != nil {
+		// the span covers the other hooks
+		hooks = append([]Hook{tracerHook{conf.Tracer}}, hooks...)
 	}
 	return &Pgr{
-		conn:   conn,
-		logger: conf.Logger,
+		conn:           conn,
+		logger:         conf.Logger,
+		requireWhere:   conf.RequireWhere,
+		softDeletes:    make(map[string]string),
+		defaultTimeout: conf.DefaultStatementTimeout,
+		retry:          conf.TransactionRetry,
+		replicas:       newReplicaSet(conf),
+		replicaWait:    conf.ReplicaWaitTimeout,
+		hooks:          hooks,
+		tracer:         conf.Tracer,
+		metrics:        newMetrics(conf.MetricsSink),
+		slowQuery:      conf.SlowQueryThreshold,
+		logSampleRate:  conf.LogSampleRate,
 	}, nil
 }
 
+// Open creates a connection pool and a new Pgr instance using it.
+// The pool settings are taken from config.
 func Open(ctx context.Context, connString string, config *Config) (*Pgr, error) {
-	conn, err := pgx.Connect(ctx, connString)
+	pc, err := poolConfig(connString, config)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := pgxpool.ConnectConfig(ctx, pc)
 	if err != nil {
 		return nil, err
 	}
-	return New(conn, config)
+	return New(pool, config)
 }
 
 const placeholder = "?"
@@ -73,7 +158,8 @@ func (p *Pgr) Conn() Conn {
 	return p.conn
 }
 
-func (p *Pgr) exec(ctx context.Context, builder Builder) (int64, error) {
+// build interpolates the builder into a query and its binary values.
+func (p *Pgr) build(ctx context.Context, builder Builder) (string, []interface{}, error) {
 	i := interpolator{
 		Buffer:       NewBuffer(),
 		IgnoreBinary: true,
@@ -81,43 +167,87 @@ func (p *Pgr) exec(ctx context.Context, builder Builder) (int64, error) {
 	err := i.encodePlaceholder(builder, true)
 	query, values := i.String(), i.Value()
 	if err != nil {
-		p.logger.Log(LogLevelError, kvs{
-			"error": err.Error(),
-			"sql":   query,
-			"args":  fmt.Sprint(values),
-		})
-		return 0, err
+		p.logger.Log(ctx, LogLevelError, "build failed",
+			ErrorField(err),
+			StringField("sql", query),
+			AnyField("args", values),
+		)
 	}
+	return query, values, err
+}
 
+// connFor returns the transaction of the context if any, or the connection.
+func (p *Pgr) connFor(ctx context.Context) Conn {
 	if tx := getTransaction(ctx); tx != nil {
-		count, err := tx.Exec(ctx, query, values...)
-		return count.RowsAffected(), err
+		return tx
+	}
+	return p.conn
+}
+
+func (p *Pgr) exec(ctx context.Context, builder Builder) (int64, error) {
+	query, values, err := p.build(ctx, builder)
+	if err != nil {
+		return 0, err
+	}
+	ctx, event, err := p.before(ctx, builder, query, values)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := p.execOn(ctx, p.connFor(ctx), builder, query, values)
+	err = pgError(p.wrapTimeout(ctx, builder, err))
+	event.RowsAffected = count.RowsAffected()
+	event.Err = err
+	p.after(ctx, event)
+
+	if err == nil {
+		p.trackWrite(ctx)
 	}
-	count, err := p.conn.Exec(ctx, query, values...)
 	return count.RowsAffected(), err
 }
 
 func (p *Pgr) queryRows(ctx context.Context, builder Builder) (string, pgx.Rows, error) {
-	i := interpolator{
-		Buffer:       NewBuffer(),
-		IgnoreBinary: true,
+	query, values, err := p.build(ctx, builder)
+	if err != nil {
+		return query, nil, err
 	}
-	err := i.encodePlaceholder(builder, true)
-	query, values := i.String(), i.Value()
+	ctx, event, err := p.before(ctx, builder, query, values)
 	if err != nil {
-		p.logger.Log(LogLevelError, kvs{
-			"error": err.Error(),
-			"sql":   query,
-			"args":  fmt.Sprint(values),
-		})
 		return query, nil, err
 	}
-	if tx := getTransaction(ctx); tx != nil {
-		rows, err := tx.Query(ctx, query, values...)
-		return query, rows, err
+
+	var rows pgx.Rows
+	if r := p.readReplica(ctx, builder); r != nil {
+		rows, err = p.queryReplica(ctx, r, builder, query, values)
+	} else {
+		rows, err = p.queryOn(ctx, p.connFor(ctx), builder, query, values)
+	}
+	if err != nil {
+		err = pgError(p.wrapTimeout(ctx, builder, err))
+		event.Err = err
+		p.after(ctx, event)
+		return query, nil, err
+	}
+	return query, &eventRows{
+		Rows:  rows,
+		ctx:   ctx,
+		db:    p,
+		event: event,
+	}, nil
+}
+
+func (p *Pgr) execOn(ctx context.Context, conn Conn, builder Builder, query string, values []interface{}) (pgconn.CommandTag, error) {
+	if timeout := p.statementTimeout(ctx, builder); timeout > 0 {
+		return p.execTimeout(ctx, conn, timeout, query, values)
+	}
+	return conn.Exec(ctx, query, values...)
+}
+
+func (p *Pgr) queryOn(ctx context.Context, conn Conn, builder Builder, query string, values []interface{}) (pgx.Rows, error) {
+	if timeout := p.statementTimeout(ctx, builder); timeout > 0 {
+		return p.queryTimeout(ctx, conn, timeout, query, values)
 	}
-	rows, err := p.conn.Query(ctx, query, values...)
-	return query, rows, err
+	return conn.Query(ctx, query, values...)
 }
 
 func (p *Pgr) query(ctx context.Context, builder Builder, dest interface{}) (int, error) {
@@ -127,11 +257,14 @@ func (p *Pgr) query(ctx context.Context, builder Builder, dest interface{}) (int
 	}
 	count, err := Load(rows, dest)
 	if err != nil {
-		p.logger.Log(LogLevelError, kvs{
-			"error": err.Error(),
-			"sql":   query,
-		})
+		p.logger.Log(ctx, LogLevelError, "load failed",
+			ErrorField(err),
+			StringField("sql", query),
+		)
 		return 0, err
 	}
+	if _, ok := builder.(*SelectBuilder); !ok {
+		p.trackWrite(ctx)
+	}
 	return count, err
 }
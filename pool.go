@@ -0,0 +1,128 @@
+package pgr
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// Stats is a snapshot of the statistics of a Pgr.
+type Stats struct {
+	// Pool holds the statistics of the connection pool,
+	// or nil if the connection is not a *pgxpool.Pool.
+	Pool *PoolStats
+	// Queries holds the statistics of the statements by fingerprint.
+	Queries map[string]QueryStats
+	// OtherQueries holds the statistics of the statements whose fingerprint
+	// is not in Queries, because too many fingerprints were seen.
+	OtherQueries QueryStats
+	// Statements holds the statistics of the statements by operation and table.
+	Statements map[StatementKey]QueryStats
+}
+
+// PoolStats is a snapshot of the statistics of a connection pool.
+type PoolStats struct {
+	AcquireCount         int64
+	AcquireDuration      time.Duration
+	CanceledAcquireCount int64
+	EmptyAcquireCount    int64
+	AcquiredConns        int32
+	ConstructingConns    int32
+	IdleConns            int32
+	TotalConns           int32
+	MaxConns             int32
+}
+
+func poolConfig(connString string, conf *Config) (*pgxpool.Config, error) {
+	pc, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, err
+	}
+	if conf == nil {
+		return pc, nil
+	}
+	if conf.MaxConns > 0 {
+		pc.MaxConns = conf.MaxConns
+	}
+	if conf.MinConns > 0 {
+		pc.MinConns = conf.MinConns
+	}
+	if conf.MaxConnLifetime > 0 {
+		pc.MaxConnLifetime = conf.MaxConnLifetime
+	}
+	if conf.MaxConnIdleTime > 0 {
+		pc.MaxConnIdleTime = conf.MaxConnIdleTime
+	}
+	if conf.HealthCheckPeriod > 0 {
+		pc.HealthCheckPeriod = conf.HealthCheckPeriod
+	}
+	return pc, nil
+}
+
+// acquire returns a connection dedicated to the caller until release is
+// called. It is taken from the pool if the connection is a *pgxpool.Pool.
+func (p *Pgr) acquire(ctx context.Context) (Conn, func(), error) {
+	if pool, ok := p.conn.(*pgxpool.Pool); ok {
+		conn, err := pool.Acquire(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		return conn, conn.Release, nil
+	}
+	return p.conn, func() {}, nil
+}
+
+// Close closes the connections of the primary and of the replicas.
+func (p *Pgr) Close() error {
+	err := closeConn(p.conn)
+	if p.replicas != nil {
+		for _, r := range p.replicas.replicas {
+			if closeErr := closeConn(r.conn); err == nil {
+				err = closeErr
+			}
+		}
+	}
+	return err
+}
+
+func closeConn(conn Conn) error {
+	switch conn := conn.(type) {
+	case *pgxpool.Pool:
+		conn.Close()
+	case *pgx.Conn:
+		return conn.Close(context.Background())
+	}
+	return nil
+}
+
+// Ping checks that the database is reachable.
+func (p *Pgr) Ping(ctx context.Context) error {
+	if conn, ok := p.conn.(interface{ Ping(context.Context) error }); ok {
+		return pgError(conn.Ping(ctx))
+	}
+	_, err := p.conn.Exec(ctx, ";")
+	return pgError(err)
+}
+
+// Stats returns a snapshot of the statistics.
+func (p *Pgr) Stats() Stats {
+	var stats Stats
+	if pool, ok := p.conn.(*pgxpool.Pool); ok {
+		s := pool.Stat()
+		stats.Pool = &PoolStats{
+			AcquireCount:         s.AcquireCount(),
+			AcquireDuration:      s.AcquireDuration(),
+			CanceledAcquireCount: s.CanceledAcquireCount(),
+			EmptyAcquireCount:    s.EmptyAcquireCount(),
+			AcquiredConns:        s.AcquiredConns(),
+			ConstructingConns:    s.ConstructingConns(),
+			IdleConns:            s.IdleConns(),
+			TotalConns:           s.TotalConns(),
+			MaxConns:             s.MaxConns(),
+		}
+	}
+	p.metrics.stats(&stats)
+	return stats
+}
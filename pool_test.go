@@ -0,0 +1,21 @@
+package pgr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolConfig(t *testing.T) {
+	pc, err := poolConfig(DB_URL, &Config{
+		MaxConns:          20,
+		MaxConnIdleTime:   time.Minute,
+		HealthCheckPeriod: 10 * time.Second,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(20), pc.MaxConns)
+	require.Equal(t, time.Minute, pc.MaxConnIdleTime)
+	require.Equal(t, 10*time.Second, pc.HealthCheckPeriod)
+	require.Equal(t, time.Hour, pc.MaxConnLifetime)
+}
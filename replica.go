@@ -0,0 +1,152 @@
+package pgr
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// ReplicaSelection is the strategy used to choose a read replica.
+type ReplicaSelection uint8
+
+const (
+	// RoundRobin uses the replicas in turn.
+	RoundRobin ReplicaSelection = iota
+	// LeastBusy uses the replica with the fewest queries in flight.
+	LeastBusy
+)
+
+const defaultReplicaRetryInterval = 5 * time.Second
+
+type replica struct {
+	conn      Conn
+	busy      int64
+	downUntil int64
+	replayed  uint64
+}
+
+type replicaSet struct {
+	replicas      []*replica
+	selection     ReplicaSelection
+	retryInterval time.Duration
+	next          uint32
+}
+
+func newReplicaSet(conf *Config) *replicaSet {
+	if len(conf.Replicas) == 0 {
+		return nil
+	}
+	s := &replicaSet{
+		selection:     conf.ReplicaSelection,
+		retryInterval: conf.ReplicaRetryInterval,
+	}
+	if s.retryInterval == 0 {
+		s.retryInterval = defaultReplicaRetryInterval
+	}
+	for _, conn := range conf.Replicas {
+		s.replicas = append(s.replicas, &replica{conn: conn})
+	}
+	return s
+}
+
+// pick returns a healthy replica, or nil if there is none.
+func (s *replicaSet) pick() *replica {
+	now := time.Now().UnixNano()
+	n := len(s.replicas)
+	start := int(atomic.AddUint32(&s.next, 1)) % n
+
+	var picked *replica
+	for i := 0; i < n; i++ {
+		r := s.replicas[(start+i)%n]
+		if atomic.LoadInt64(&r.downUntil) > now {
+			continue
+		}
+		if s.selection == RoundRobin {
+			return r
+		}
+		if picked == nil || atomic.LoadInt64(&r.busy) < atomic.LoadInt64(&picked.busy) {
+			picked = r
+		}
+	}
+	return picked
+}
+
+// failed skips the replica until the retry interval has elapsed.
+func (s *replicaSet) failed(r *replica) {
+	atomic.StoreInt64(&r.downUntil, time.Now().Add(s.retryInterval).UnixNano())
+}
+
+// connFailed reports whether err is caused by the connection rather than
+// by the query or the context.
+func connFailed(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	return !errors.As(err, &pgErr) && !errors.Is(err, ErrStatementTimeout)
+}
+
+// readReplica returns the replica to run the builder on, or nil if it must
+// run on the primary: outside of selects, in transactions, for selects
+// marked with Primary, for raw selects not marked with Replica and when the
+// replica is behind the consistency token.
+func (p *Pgr) readReplica(ctx context.Context, builder Builder) *replica {
+	if p.replicas == nil || getTxState(ctx) != nil {
+		return nil
+	}
+	b, ok := builder.(*SelectBuilder)
+	if !ok || b.primary || (b.raw.Query != "" && !b.replica) {
+		return nil
+	}
+	r := p.replicas.pick()
+	if r == nil || !p.caughtUp(ctx, r) {
+		return nil
+	}
+	return r
+}
+
+// queryReplica runs the query on the replica, and falls back to the primary
+// if the replica is unreachable.
+func (p *Pgr) queryReplica(ctx context.Context, r *replica, builder Builder, query string, values []interface{}) (pgx.Rows, error) {
+	atomic.AddInt64(&r.busy, 1)
+	rows, err := p.queryOn(ctx, r.conn, builder, query, values)
+	if err != nil {
+		atomic.AddInt64(&r.busy, -1)
+		if connFailed(ctx, err) {
+			p.replicas.failed(r)
+			return p.queryOn(ctx, p.connFor(ctx), builder, query, values)
+		}
+		return nil, err
+	}
+	return &replicaRows{
+		Rows:     rows,
+		ctx:      ctx,
+		replicas: p.replicas,
+		replica:  r,
+	}, nil
+}
+
+// replicaRows tracks the queries in flight on a replica.
+type replicaRows struct {
+	pgx.Rows
+	ctx      context.Context
+	replicas *replicaSet
+	replica  *replica
+	closed   bool
+}
+
+func (r *replicaRows) Close() {
+	if r.closed {
+		return
+	}
+	r.closed = true
+	r.Rows.Close()
+	atomic.AddInt64(&r.replica.busy, -1)
+	if connFailed(r.ctx, r.Rows.Err()) {
+		r.replicas.failed(r.replica)
+	}
+}
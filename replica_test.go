@@ -0,0 +1,59 @@
+package pgr
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplicaSet(t *testing.T) {
+	t.Run("round robin", func(t *testing.T) {
+		s := newReplicaSet(&Config{Replicas: []Conn{nil, nil, nil}})
+		first := s.pick()
+		second := s.pick()
+		require.NotSame(t, first, second)
+
+		s.failed(s.replicas[0])
+		s.failed(s.replicas[1])
+		for i := 0; i < 3; i++ {
+			require.Same(t, s.replicas[2], s.pick())
+		}
+
+		s.failed(s.replicas[2])
+		require.Nil(t, s.pick())
+	})
+
+	t.Run("least busy", func(t *testing.T) {
+		s := newReplicaSet(&Config{
+			Replicas:         []Conn{nil, nil},
+			ReplicaSelection: LeastBusy,
+		})
+		s.replicas[0].busy = 2
+		for i := 0; i < 3; i++ {
+			require.Same(t, s.replicas[1], s.pick())
+		}
+	})
+
+	t.Run("routing", func(t *testing.T) {
+		db, err := New(nil, &Config{Replicas: []Conn{nil}})
+		require.NoError(t, err)
+		ctx := context.Background()
+
+		require.NotNil(t, db.readReplica(ctx, db.Select("1")))
+		require.Nil(t, db.readReplica(ctx, db.Select("1").Primary()))
+		require.Nil(t, db.readReplica(ctx, db.SelectSql("SELECT nextval('users_id_seq')")))
+		require.NotNil(t, db.readReplica(ctx, db.SelectSql("SELECT 1").Replica()))
+		require.Nil(t, db.readReplica(ctx, db.DeleteFrom("users")))
+		require.Nil(t, db.readReplica(context.WithValue(ctx, transactionKey, &txState{}), db.Select("1")))
+	})
+
+	t.Run("connection failures", func(t *testing.T) {
+		ctx := context.Background()
+		require.True(t, connFailed(ctx, errors.New("connection reset")))
+		require.False(t, connFailed(ctx, &pgconn.PgError{Code: "42P01"}))
+		require.False(t, connFailed(ctx, nil))
+	})
+}
@@ -3,6 +3,7 @@ package pgr
 import (
 	"context"
 	"strconv"
+	"time"
 
 	"github.com/jackc/pgx/v4"
 )
@@ -11,7 +12,12 @@ type SelectBuilder struct {
 	db *Pgr
 	raw
 
+	timeout time.Duration
+
 	distinct bool
+	deleted  deletedMode
+	primary  bool
+	replica  bool
 
 	columns    []interface{}
 	table      interface{}
@@ -156,28 +162,91 @@ func (b *SelectBuilder) OrderDir(col string, isAsc bool) *SelectBuilder {
 // Join add inner join.
 // on can be Builder or string.
 func (b *SelectBuilder) Join(table, on interface{}) *SelectBuilder {
-	b.joinTables = append(b.joinTables, join(inner, table, on))
+	b.joinTables = append(b.joinTables, b.join(inner, table, on))
 	return b
 }
 
 // LeftJoin add left join.
 // on can be Builder or string.
 func (b *SelectBuilder) LeftJoin(table, on interface{}) *SelectBuilder {
-	b.joinTables = append(b.joinTables, join(left, table, on))
+	b.joinTables = append(b.joinTables, b.join(left, table, on))
 	return b
 }
 
 // RightJoin add right join.
 // on can be Builder or string.
 func (b *SelectBuilder) RightJoin(table, on interface{}) *SelectBuilder {
-	b.joinTables = append(b.joinTables, join(right, table, on))
+	b.joinTables = append(b.joinTables, b.join(right, table, on))
 	return b
 }
 
 // FullJoin add full join.
 // on can be Builder or string.
 func (b *SelectBuilder) FullJoin(table, on interface{}) *SelectBuilder {
-	b.joinTables = append(b.joinTables, join(full, table, on))
+	b.joinTables = append(b.joinTables, b.join(full, table, on))
+	return b
+}
+
+// WithDeleted includes soft deleted rows of the selected and joined tables.
+func (b *SelectBuilder) WithDeleted() *SelectBuilder {
+	b.deleted = withDeleted
+	return b
+}
+
+// OnlyDeleted selects only soft deleted rows.
+// Joined tables are not filtered.
+func (b *SelectBuilder) OnlyDeleted() *SelectBuilder {
+	b.deleted = onlyDeleted
+	return b
+}
+
+// join adds the soft delete condition of the joined table to on.
+func (b *SelectBuilder) join(t joinType, table, on interface{}) Builder {
+	return BuildFunc(func(buf Buffer) error {
+		name, ok := table.(string)
+		if !ok || b.deleted != withoutDeleted {
+			return join(t, table, on).Build(buf)
+		}
+		column := b.db.softDeleteColumn(name)
+		if column == "" {
+			return join(t, table, on).Build(buf)
+		}
+		cond := deletedCond(name, column, false)
+		switch on := on.(type) {
+		case string:
+			return join(t, table, And(Expr(on), cond)).Build(buf)
+		case Builder:
+			return join(t, table, And(on, cond)).Build(buf)
+		}
+		return join(t, table, cond).Build(buf)
+	})
+}
+
+// deletedCond returns the soft delete condition of the selected table,
+// or nil if it has none.
+func (b *SelectBuilder) deletedCond() Builder {
+	table, ok := b.table.(string)
+	if !ok || b.deleted == withDeleted {
+		return nil
+	}
+	name, alias := splitAlias(table)
+	column := b.db.softDeleteColumn(name)
+	if column == "" {
+		return nil
+	}
+	return deletedCond(alias, column, b.deleted == onlyDeleted)
+}
+
+// Primary runs the query on the primary connection instead of a replica.
+func (b *SelectBuilder) Primary() *SelectBuilder {
+	b.primary = true
+	return b
+}
+
+// Replica allows a query of SelectSql to run on a replica. Raw queries run on
+// the primary by default, since they may lock or write rows.
+func (b *SelectBuilder) Replica() *SelectBuilder {
+	b.replica = true
 	return b
 }
 
@@ -192,6 +261,18 @@ func (b *SelectBuilder) Rows(ctx context.Context) (pgx.Rows, error) {
 	return rows, err
 }
 
+// Each executes the query and loads the records one at a time into given
+// struct, calling fn after each of them.
+// It stops at the first error returned by fn and returns it.
+func (b *SelectBuilder) Each(ctx context.Context, dest interface{}, fn func() error) error {
+	_, rows, err := b.db.queryRows(ctx, b)
+	if err != nil {
+		return err
+	}
+	_, err = Each(rows, dest, fn)
+	return err
+}
+
 // LoadOne executes the query and loads one record into given struct.
 func (b *SelectBuilder) LoadOne(ctx context.Context, dest interface{}) error {
 	count, err := b.db.query(ctx, b, dest)
@@ -209,6 +290,21 @@ func (b *SelectBuilder) Load(ctx context.Context, dest interface{}) (int, error)
 	return b.db.query(ctx, b, dest)
 }
 
+// Timeout sets the statement timeout of the query, which is enforced by the
+// server and reported as ErrStatementTimeout.
+func (b *SelectBuilder) Timeout(d time.Duration) *SelectBuilder {
+	b.timeout = d
+	return b
+}
+
+func (b *SelectBuilder) statementTimeout() time.Duration {
+	return b.timeout
+}
+
+func (b *SelectBuilder) pgr() *Pgr {
+	return b.db
+}
+
 func (b *SelectBuilder) Build(buf Buffer) error {
 	if b.raw.Query != "" {
 		return b.raw.Build(buf)
@@ -239,13 +335,7 @@ func (b *SelectBuilder) Build(buf Buffer) error {
 
 	if b.table != nil {
 		buf.WriteString(" FROM ")
-		switch table := b.table.(type) {
-		case string:
-			buf.WriteString(table)
-		default:
-			buf.WriteString(placeholder)
-			buf.WriteValue(table)
-		}
+		buildTable(buf, b.table)
 
 		if len(b.joinTables) > 0 {
 			for _, join := range b.joinTables {
@@ -257,9 +347,14 @@ func (b *SelectBuilder) Build(buf Buffer) error {
 		}
 	}
 
-	if len(b.whereCond) > 0 {
+	whereCond := b.whereCond
+	if cond := b.deletedCond(); cond != nil {
+		whereCond = append(whereCond[:len(whereCond):len(whereCond)], cond)
+	}
+
+	if len(whereCond) > 0 {
 		buf.WriteString(" WHERE ")
-		err := And(b.whereCond...).Build(buf)
+		err := And(whereCond...).Build(buf)
 		if err != nil {
 			return err
 		}
@@ -2,8 +2,11 @@ package pgr
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/jackc/pgx/v4"
 	"github.com/stretchr/testify/require"
 )
 
@@ -38,6 +41,36 @@ func TestSelectBuilder(t *testing.T) {
 			buf.String())
 		require.Equal(t, 4, len(buf.Value()))
 	})
+
+	t.Run("select soft deleted", func(t *testing.T) {
+		db := getDb()
+		db.SoftDelete("users", "deleted_at")
+		db.SoftDelete("movies", "deleted_at")
+
+		buf := NewBuffer()
+		err := db.Select("u.name", "movies.name").
+			From("users u").
+			Join("movies", "movies.id = u.movie_id").
+			Where(Eq("u.age", 1)).
+			Build(buf)
+		require.NoError(t, err)
+
+		query, err := Interpolate(buf.String(), buf.Value())
+		require.NoError(t, err)
+		require.Equal(t, `SELECT u.name, movies.name FROM users u `+
+			`JOIN "movies" ON (movies.id = u.movie_id) AND ("movies"."deleted_at" IS NULL) `+
+			`WHERE ("u"."age" = 1) AND ("u"."deleted_at" IS NULL)`, query)
+
+		buf = NewBuffer()
+		err = db.Select("name").From("users").OnlyDeleted().Build(buf)
+		require.NoError(t, err)
+		require.Equal(t, `SELECT name FROM users WHERE ("users"."deleted_at" IS NOT NULL)`, buf.String())
+
+		buf = NewBuffer()
+		err = db.Select("name").From("users").Join("movies", "true").WithDeleted().Build(buf)
+		require.NoError(t, err)
+		require.Equal(t, `SELECT name FROM users JOIN "movies" ON true`, buf.String())
+	})
 }
 
 func TestSelect(t *testing.T) {
@@ -150,6 +183,147 @@ func TestSelectLoad(t *testing.T) {
 	require.Equal(t, expected, users)
 }
 
+func TestSelectEach(t *testing.T) {
+	db := getDb()
+	ctx := context.Background()
+	_, err := db.InsertInto("users").
+		Columns("name", "age").
+		Values("a", 1).
+		Values("b", 2).
+		Values("c", 3).
+		Exec(ctx)
+	require.NoError(t, err)
+
+	var user User
+	var names []string
+	err = db.Select("name", "age").
+		From("users").
+		OrderAsc("id").
+		Each(ctx, &user, func() error {
+			names = append(names, user.Name)
+			return nil
+		})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b", "c"}, names)
+
+	stop := errors.New("stop")
+	count := 0
+	err = db.Select("name").
+		From("users").
+		Each(ctx, &user, func() error {
+			count++
+			return stop
+		})
+	require.ErrorIs(t, err, stop)
+	require.Equal(t, 1, count)
+}
+
+func TestSelectCursor(t *testing.T) {
+	db := getDb()
+	ctx := context.Background()
+	_, err := db.InsertInto("users").
+		Columns("name", "age").
+		Values("a", 1).
+		Values("b", 2).
+		Values("c", 3).
+		Exec(ctx)
+	require.NoError(t, err)
+
+	cursor, err := db.Select("name", "age").From("users").OrderAsc("id").Cursor(ctx, 2)
+	require.NoError(t, err)
+
+	var batches [][]User
+	for {
+		var users []User
+		count, err := cursor.Next(ctx, &users)
+		require.NoError(t, err)
+		if count == 0 {
+			break
+		}
+		batches = append(batches, users)
+	}
+	require.NoError(t, cursor.Close(ctx))
+
+	require.Equal(t, 2, len(batches))
+	require.Equal(t, 2, len(batches[0]))
+	require.Equal(t, "c", batches[1][0].Name)
+
+	_, err = db.Select("name").From("users").Cursor(ctx, 0)
+	require.ErrorIs(t, err, ErrInvalidBatchSize)
+	_, err = db.Select("name").From("users").Cursor(ctx, -1)
+	require.ErrorIs(t, err, ErrInvalidBatchSize)
+}
+
+func TestSelectCursorHooks(t *testing.T) {
+	var calls []string
+	hook := &recordingHook{name: "hook", calls: &calls}
+	db, err := New(getDb().Conn(), &Config{Hooks: []Hook{hook}})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	cursor, err := db.SelectSql("SELECT n FROM generate_series(1, 3) n").Cursor(ctx, 2)
+	require.NoError(t, err)
+	var batch []int
+	_, err = cursor.Next(ctx, &batch)
+	require.NoError(t, err)
+	require.NoError(t, cursor.Close(ctx))
+
+	require.Len(t, hook.events, 2)
+	require.Contains(t, hook.events[0].SQL, "DECLARE")
+	require.Contains(t, hook.events[1].SQL, "FETCH 2")
+	require.Equal(t, int64(2), hook.events[1].RowsAffected)
+
+	cursor, err = db.SelectSql("SELECT pg_sleep(1)::text").Timeout(10*time.Millisecond).Cursor(ctx, 1)
+	require.NoError(t, err)
+	var slept []string
+	_, err = cursor.Next(ctx, &slept)
+	require.ErrorIs(t, err, ErrStatementTimeout)
+	cursor.Close(ctx)
+}
+
+func TestSelectCursorWithHold(t *testing.T) {
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, DB_URL)
+	require.NoError(t, err)
+	defer conn.Close(ctx)
+	db, err := New(conn, nil)
+	require.NoError(t, err)
+
+	cursor, err := db.SelectSql("SELECT n FROM generate_series(1, 5) n").Cursor(ctx, 2, WithHold())
+	require.NoError(t, err)
+
+	var all []int
+	for {
+		var batch []int
+		count, err := cursor.Next(ctx, &batch)
+		require.NoError(t, err)
+		if count == 0 {
+			break
+		}
+		all = append(all, batch...)
+
+		// the cursor is still readable after other transactions commit
+		err = db.Transaction(ctx, func(ctx context.Context) error {
+			_, err := Scalar[int](ctx, db.SelectSql("SELECT 1"))
+			return err
+		})
+		require.NoError(t, err)
+	}
+	require.NoError(t, cursor.Close(ctx))
+	require.Equal(t, []int{1, 2, 3, 4, 5}, all)
+}
+
+func TestSelectTimeout(t *testing.T) {
+	db := getDb()
+	ctx := context.Background()
+
+	var slept []string
+	_, err := db.SelectSql("SELECT pg_sleep(1)::text").
+		Timeout(10*time.Millisecond).
+		Load(ctx, &slept)
+	require.ErrorIs(t, err, ErrStatementTimeout)
+}
+
 func strPtr(s string) *string {
 	return &s
 }
@@ -0,0 +1,90 @@
+package pgr
+
+import (
+	"context"
+	"reflect"
+)
+
+// Snapshot remembers the column values of a struct, so that an update can be
+// limited to the columns that changed since, without overwriting concurrent
+// edits to the other columns.
+type Snapshot struct {
+	record interface{}
+	values map[string]interface{}
+}
+
+// Track takes a snapshot of record, which must be a pointer to a struct.
+func Track(record interface{}) (*Snapshot, error) {
+	v := reflect.ValueOf(record)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil, ErrInvalidPointer
+	}
+	return &Snapshot{
+		record: record,
+		values: columnValues(record),
+	}, nil
+}
+
+// Changed returns the columns whose value differs from the snapshot.
+func (s *Snapshot) Changed() []string {
+	var changed []string
+	current := columnValues(s.record)
+	for _, col := range newTagStore().get(reflect.Indirect(reflect.ValueOf(s.record)).Type()) {
+		if col == "" {
+			continue
+		}
+		if !reflect.DeepEqual(s.values[col], current[col]) {
+			changed = append(changed, col)
+		}
+	}
+	return changed
+}
+
+// LoadSnapshot executes the query, loads one record into given struct
+// and takes a snapshot of it.
+func (b *SelectBuilder) LoadSnapshot(ctx context.Context, dest interface{}) (*Snapshot, error) {
+	err := b.LoadOne(ctx, dest)
+	if err != nil {
+		return nil, err
+	}
+	return Track(dest)
+}
+
+func columnValues(record interface{}) map[string]interface{} {
+	v := reflect.Indirect(reflect.ValueOf(record))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	columns := newTagStore().get(v.Type())
+	m := make(map[string]interface{}, len(columns))
+	for i, col := range columns {
+		if col == "" || !v.Field(i).CanInterface() {
+			continue
+		}
+		m[col] = cloneValue(v.Field(i))
+	}
+	return m
+}
+
+// cloneValue copies slices and pointers, so that changes made in place
+// are not reflected in the snapshot.
+func cloneValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			break
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(c, v)
+		return c.Interface()
+	case reflect.Ptr:
+		if v.IsNil() {
+			break
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(v.Elem())
+		return c.Interface()
+	}
+	return v.Interface()
+}
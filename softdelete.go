@@ -0,0 +1,82 @@
+package pgr
+
+import (
+	"reflect"
+	"strings"
+)
+
+type deletedMode uint8
+
+const (
+	withoutDeleted deletedMode = iota
+	withDeleted
+	onlyDeleted
+)
+
+// SoftDelete registers table as soft deleted through a timestamp column.
+//
+// DeleteFrom on the table sets the column to now() instead of deleting rows,
+// and Select on the table, or joins with it, only return rows where the
+// column is NULL.
+//
+// Tables must be registered before the Pgr is used concurrently.
+func (db *Pgr) SoftDelete(table, column string) {
+	db.softDeletes[table] = column
+}
+
+// SoftDeleteModel registers table as soft deleted through the column of the
+// model field tagged with `db:"column,softdelete"`.
+// model must be a struct or a pointer to a struct.
+func (db *Pgr) SoftDeleteModel(table string, model interface{}) error {
+	v := reflect.ValueOf(model)
+	if !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		return ErrInvalidPointer
+	}
+	t := reflect.Indirect(v).Type()
+	if t.Kind() != reflect.Struct {
+		return ErrNotSupported
+	}
+	column, ok := newTagStore().tagged(t, "softdelete")
+	if !ok {
+		return ErrColumnNotSpecified
+	}
+	db.SoftDelete(table, column)
+	return nil
+}
+
+func (db *Pgr) softDeleteColumn(table string) string {
+	if db == nil {
+		return ""
+	}
+	return db.softDeletes[table]
+}
+
+// deletedCond builds `"table"."column" IS [NOT] NULL`.
+func deletedCond(table, column string, deleted bool) Builder {
+	return BuildFunc(func(buf Buffer) error {
+		buf.WriteString(QuoteIdent(table))
+		buf.WriteString(".")
+		buf.WriteString(QuoteIdent(column))
+		if deleted {
+			buf.WriteString(" IS NOT NULL")
+		} else {
+			buf.WriteString(" IS NULL")
+		}
+		return nil
+	})
+}
+
+// splitAlias splits `table [AS] alias` into the table name and the name
+// that qualifies its columns.
+func splitAlias(table string) (string, string) {
+	part := strings.Fields(table)
+	switch {
+	case len(part) == 2:
+		return part[0], part[1]
+	case len(part) == 3 && strings.EqualFold(part[1], "AS"):
+		return part[0], part[2]
+	case len(part) > 0:
+		return part[0], part[0]
+	}
+	return table, table
+}
@@ -0,0 +1,47 @@
+package pgr
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	fingerprintLiteral = regexp.MustCompile(`[Ee]?'(?:[^']|'')*'|\$\d+|\b\d+(?:\.\d+)?\b`)
+	fingerprintList    = regexp.MustCompile(`\(\?(?:\s*,\s*\?)+\)`)
+	fingerprintTuples  = regexp.MustCompile(`\(\?\)(?:\s*,\s*\(\?\))+`)
+	fingerprintSpace   = regexp.MustCompile(`\s+`)
+)
+
+// Fingerprint normalizes a SQL statement so that statements which only
+// differ by their values are the same: literals and placeholders are
+// replaced with ?, lists of values and of rows are collapsed and spaces
+// are squeezed.
+func Fingerprint(sql string) string {
+	sql = fingerprintLiteral.ReplaceAllString(sql, "?")
+	sql = fingerprintList.ReplaceAllString(sql, "(?)")
+	sql = fingerprintTuples.ReplaceAllString(sql, "(?)")
+	sql = fingerprintSpace.ReplaceAllString(sql, " ")
+	return strings.TrimSpace(sql)
+}
+
+// statementInfo returns the kind of statement of the builder
+// (select, insert, update, delete, copy or query) and its table, if known.
+func statementInfo(builder Builder) (string, string) {
+	switch b := builder.(type) {
+	case *SelectBuilder:
+		var table string
+		if s, ok := b.table.(string); ok {
+			table, _ = splitAlias(s)
+		}
+		return "select", table
+	case *InsertBuilder:
+		return "insert", b.table
+	case *UpdateBuilder:
+		return "update", b.table
+	case *DeleteBuilder:
+		return "delete", b.table
+	case *copyStatement:
+		return "copy", b.table
+	}
+	return "query", ""
+}
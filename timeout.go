@@ -0,0 +1,162 @@
+package pgr
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// timeoutGrace is added to the statement timeout for the context deadline,
+// so that the server cancels the statement before the client gives up
+// and closes the connection.
+const timeoutGrace = time.Second
+
+const (
+	setTimeoutSql = "SELECT set_config('pgr.statement_timeout', current_setting('statement_timeout'), true), " +
+		"set_config('statement_timeout', $1, true)"
+	resetTimeoutSql = "SELECT set_config('statement_timeout', current_setting('pgr.statement_timeout'), true)"
+)
+
+type timeoutError struct {
+	err error
+}
+
+func (e *timeoutError) Error() string {
+	return ErrStatementTimeout.Error() + ": " + e.err.Error()
+}
+
+func (e *timeoutError) Is(target error) bool {
+	return target == ErrStatementTimeout
+}
+
+func (e *timeoutError) Unwrap() error {
+	return e.err
+}
+
+// statementTimeout returns the timeout to enforce for the builder.
+// The default timeout of a transaction is set when it begins.
+func (p *Pgr) statementTimeout(ctx context.Context, builder Builder) time.Duration {
+	if b, ok := builder.(interface{ statementTimeout() time.Duration }); ok && b.statementTimeout() > 0 {
+		return b.statementTimeout()
+	}
+	if getTransaction(ctx) == nil {
+		return p.defaultTimeout
+	}
+	return 0
+}
+
+// wrapTimeout turns the timeout errors of the builder into
+// ErrStatementTimeout, when a timeout is in effect for it: its own, the
+// default one or the default one of the transaction of the context.
+func (p *Pgr) wrapTimeout(ctx context.Context, builder Builder, err error) error {
+	if p.statementTimeout(ctx, builder) > 0 || (p.defaultTimeout > 0 && getTransaction(ctx) != nil) {
+		return checkTimeout(ctx, err)
+	}
+	return err
+}
+
+// queueTimeout queues the query into batch, surrounded by the statements
+// setting and resetting statement_timeout if timeout is not zero.
+func queueTimeout(batch *pgx.Batch, timeout time.Duration, query string, values []interface{}) {
+	if timeout > 0 {
+		batch.Queue(setTimeoutSql, strconv.FormatInt(timeout.Milliseconds(), 10))
+	}
+	batch.Queue(query, values...)
+	if timeout > 0 {
+		batch.Queue(resetTimeoutSql)
+	}
+}
+
+// timeoutBatch sets statement_timeout locally for the query, which runs
+// in the transaction of the context or in the implicit transaction of the batch.
+func timeoutBatch(timeout time.Duration, query string, values []interface{}) *pgx.Batch {
+	batch := &pgx.Batch{}
+	queueTimeout(batch, timeout, query, values)
+	return batch
+}
+
+func (p *Pgr) execTimeout(ctx context.Context, conn Conn, timeout time.Duration, query string, values []interface{}) (pgconn.CommandTag, error) {
+	tctx, cancel := context.WithTimeout(ctx, timeout+timeoutGrace)
+	defer cancel()
+
+	results := conn.SendBatch(tctx, timeoutBatch(timeout, query, values))
+	_, err := results.Exec()
+	var tag pgconn.CommandTag
+	if err == nil {
+		tag, err = results.Exec()
+	}
+	if err == nil {
+		_, err = results.Exec()
+	}
+	closeErr := results.Close()
+	if err == nil {
+		err = closeErr
+	}
+	return tag, checkTimeout(ctx, err)
+}
+
+func (p *Pgr) queryTimeout(ctx context.Context, conn Conn, timeout time.Duration, query string, values []interface{}) (pgx.Rows, error) {
+	tctx, cancel := context.WithTimeout(ctx, timeout+timeoutGrace)
+
+	results := conn.SendBatch(tctx, timeoutBatch(timeout, query, values))
+	_, err := results.Exec()
+	var rows pgx.Rows
+	if err == nil {
+		rows, err = results.Query()
+	}
+	if err != nil {
+		results.Close()
+		cancel()
+		return nil, checkTimeout(ctx, err)
+	}
+	return &timeoutRows{
+		Rows:    rows,
+		ctx:     ctx,
+		results: results,
+		cancel:  cancel,
+	}, nil
+}
+
+// timeoutRows reads the rows of a query sent with timeoutBatch.
+type timeoutRows struct {
+	pgx.Rows
+	ctx     context.Context
+	results pgx.BatchResults
+	cancel  context.CancelFunc
+	closed  bool
+}
+
+func (r *timeoutRows) Close() {
+	if r.closed {
+		return
+	}
+	r.closed = true
+	r.Rows.Close()
+	r.results.Exec()
+	r.results.Close()
+	r.cancel()
+}
+
+func (r *timeoutRows) Err() error {
+	return checkTimeout(r.ctx, r.Rows.Err())
+}
+
+// checkTimeout turns errors caused by the statement timeout into
+// ErrStatementTimeout. ctx is the context given by the caller.
+func checkTimeout(ctx context.Context, err error) error {
+	if err == nil || errors.Is(err, ErrStatementTimeout) {
+		return err
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "57014" {
+		return &timeoutError{err}
+	}
+	if pgconn.Timeout(err) && ctx.Err() == nil {
+		return &timeoutError{err}
+	}
+	return err
+}
@@ -0,0 +1,160 @@
+package pgr
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Attribute is a key/value pair attached to a span.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Attribute keys set on spans.
+const (
+	AttrOperation    = "db.operation"
+	AttrTable        = "db.sql.table"
+	AttrStatement    = "db.statement"
+	AttrRowsAffected = "db.rows_affected"
+	AttrBatchSize    = "db.batch.size"
+)
+
+// Span is a traced unit of work.
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Tracer starts a span for each statement, transaction, batch and copy.
+// It is registered in the Config, and adapts pgr to a tracing backend.
+type Tracer interface {
+	// Start starts a span, and returns a context carrying it
+	// so that the spans started with the context are its children.
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...Attribute) {}
+func (noopSpan) RecordError(error)          {}
+func (noopSpan) End()                       {}
+
+func (p *Pgr) startSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	if p.tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return p.tracer.Start(ctx, name, attrs...)
+}
+
+const spanKey contextKey = "span"
+
+// tracerHook starts a span for each statement of a builder.
+type tracerHook struct {
+	tracer Tracer
+}
+
+func (h tracerHook) BeforeQuery(ctx context.Context, event *QueryEvent) (context.Context, error) {
+	kind, table := statementInfo(event.Builder)
+	attrs := []Attribute{
+		{AttrOperation, kind},
+		{AttrStatement, event.Fingerprint},
+	}
+	if table != "" {
+		attrs = append(attrs, Attribute{AttrTable, table})
+	}
+	ctx, span := h.tracer.Start(ctx, "pgr."+kind, attrs...)
+	return context.WithValue(ctx, spanKey, span), nil
+}
+
+func (h tracerHook) AfterQuery(ctx context.Context, event *QueryEvent) {
+	span, ok := ctx.Value(spanKey).(Span)
+	if !ok {
+		return
+	}
+	span.SetAttributes(Attribute{AttrRowsAffected, event.RowsAffected})
+	if event.Err != nil {
+		span.RecordError(event.Err)
+	}
+	span.End()
+}
+
+// RecordingTracer is a Tracer which keeps the spans in memory, for tests.
+type RecordingTracer struct {
+	mu    sync.Mutex
+	spans []*RecordedSpan
+}
+
+// RecordedSpan is a span recorded by a RecordingTracer.
+type RecordedSpan struct {
+	Name       string
+	Parent     *RecordedSpan
+	Attributes map[string]interface{}
+	Err        error
+	Start      time.Time
+	End        time.Time
+
+	tracer *RecordingTracer
+}
+
+const recordedSpanKey contextKey = "recorded_span"
+
+// NewRecordingTracer creates an empty RecordingTracer.
+func NewRecordingTracer() *RecordingTracer {
+	return &RecordingTracer{}
+}
+
+// Start implements Tracer.
+func (t *RecordingTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	parent, _ := ctx.Value(recordedSpanKey).(*RecordedSpan)
+	s := &recordingSpan{&RecordedSpan{
+		Name:       name,
+		Parent:     parent,
+		Attributes: make(map[string]interface{}),
+		Start:      time.Now(),
+		tracer:     t,
+	}}
+	s.SetAttributes(attrs...)
+	return context.WithValue(ctx, recordedSpanKey, s.RecordedSpan), s
+}
+
+// Spans returns the ended spans, in the order they ended.
+func (t *RecordingTracer) Spans() []*RecordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*RecordedSpan(nil), t.spans...)
+}
+
+// Reset drops the recorded spans.
+func (t *RecordingTracer) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spans = nil
+}
+
+type recordingSpan struct {
+	*RecordedSpan
+}
+
+func (s recordingSpan) SetAttributes(attrs ...Attribute) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	for _, attr := range attrs {
+		s.Attributes[attr.Key] = attr.Value
+	}
+}
+
+func (s recordingSpan) RecordError(err error) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.Err = err
+}
+
+func (s recordingSpan) End() {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.RecordedSpan.End = time.Now()
+	s.tracer.spans = append(s.tracer.spans, s.RecordedSpan)
+}
@@ -0,0 +1,91 @@
+package pgr
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprint(t *testing.T) {
+	for _, test := range []struct {
+		sql  string
+		want string
+	}{
+		{
+			sql:  `SELECT * FROM "users" WHERE ("id" = 1)`,
+			want: `SELECT * FROM "users" WHERE ("id" = ?)`,
+		},
+		{
+			sql:  `SELECT * FROM "users" WHERE ("name" = 'it''s') AND ("age" > 2.5)`,
+			want: `SELECT * FROM "users" WHERE ("name" = ?) AND ("age" > ?)`,
+		},
+		{
+			sql:  "SELECT * FROM \"t1\"\n  WHERE (\"id\" IN (1,2, 3)) AND data = $1",
+			want: `SELECT * FROM "t1" WHERE ("id" IN (?)) AND data = ?`,
+		},
+		{
+			sql:  `INSERT INTO "users" ("name","age") VALUES ('a',1), ('b',2)`,
+			want: `INSERT INTO "users" ("name","age") VALUES (?)`,
+		},
+		{
+			sql:  `INSERT INTO "users" ("name") VALUES ('a'),('b'),('c') RETURNING "id"`,
+			want: `INSERT INTO "users" ("name") VALUES (?) RETURNING "id"`,
+		},
+	} {
+		require.Equal(t, test.want, Fingerprint(test.sql))
+	}
+}
+
+func TestRecordingTracer(t *testing.T) {
+	tracer := NewRecordingTracer()
+	denied := errors.New("denied")
+	db, err := New(nil, &Config{
+		Tracer: tracer,
+		Hooks:  []Hook{&recordingHook{calls: &[]string{}, abort: denied}},
+	})
+	require.NoError(t, err)
+
+	_, err = db.Update("users").Set("name", "a").Where(Eq("id", 1)).Exec(context.Background())
+	require.ErrorIs(t, err, denied)
+
+	spans := tracer.Spans()
+	require.Len(t, spans, 1)
+	require.Equal(t, "pgr.update", spans[0].Name)
+	require.Equal(t, "update", spans[0].Attributes[AttrOperation])
+	require.Equal(t, "users", spans[0].Attributes[AttrTable])
+	require.Equal(t, `UPDATE "users" SET "name" = ? WHERE ("id" = ?)`, spans[0].Attributes[AttrStatement])
+	require.ErrorIs(t, spans[0].Err, denied)
+
+	tracer.Reset()
+	require.Empty(t, tracer.Spans())
+}
+
+func TestTracer(t *testing.T) {
+	tracer := NewRecordingTracer()
+	db, err := New(getDb().Conn(), &Config{Tracer: tracer})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	err = db.Transaction(ctx, func(ctx context.Context) error {
+		_, err := db.InsertInto("users").Columns("name", "age").Values("traced", 20).Exec(ctx)
+		if err != nil {
+			return err
+		}
+		var users []User
+		_, err = db.Select("*").From("users u").Where(Eq("name", "traced")).Load(ctx, &users)
+		return err
+	})
+	require.NoError(t, err)
+
+	spans := tracer.Spans()
+	require.Len(t, spans, 3)
+	require.Equal(t, "pgr.insert", spans[0].Name)
+	require.Equal(t, int64(1), spans[0].Attributes[AttrRowsAffected])
+	require.Equal(t, "pgr.select", spans[1].Name)
+	require.Equal(t, "users", spans[1].Attributes[AttrTable])
+	require.Equal(t, "pgr.transaction", spans[2].Name)
+	require.Same(t, spans[2], spans[0].Parent)
+	require.Same(t, spans[2], spans[1].Parent)
+}
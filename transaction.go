@@ -2,7 +2,12 @@ package pgr
 
 import (
 	"context"
+	"errors"
+	"math/rand"
+	"strconv"
+	"time"
 
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 )
 
@@ -10,20 +15,245 @@ type contextKey string
 
 const transactionKey contextKey = "transaction"
 
+// RetryPolicy configures how Transaction retries transient failures.
+// The callback runs again from scratch, with a new transaction.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	// Zero or one disables retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. It doubles after each
+	// attempt, and a random jitter of up to half of it is applied.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts. Zero means no cap.
+	MaxDelay time.Duration
+	// Codes are the SQLSTATE codes to retry.
+	// It defaults to serialization_failure and deadlock_detected.
+	Codes []string
+}
+
+var defaultRetryCodes = []string{
+	"40001", // serialization_failure
+	"40P01", // deadlock_detected
+}
+
+func (r RetryPolicy) retryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	codes := r.Codes
+	if len(codes) == 0 {
+		codes = defaultRetryCodes
+	}
+	return contains(codes, pgErr.Code)
+}
+
+func (r RetryPolicy) delay(attempt int) time.Duration {
+	d := r.BaseDelay
+	for i := 1; i < attempt && (r.MaxDelay == 0 || d < r.MaxDelay); i++ {
+		d *= 2
+	}
+	if r.MaxDelay > 0 && d > r.MaxDelay {
+		d = r.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// txBeginner is implemented by the connections which support
+// transaction options, like *pgx.Conn and *pgxpool.Pool.
+type txBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// Transaction runs fn in a transaction, which is committed if fn returns nil
+// and rolled back otherwise. Queries run with the context given to fn use
+// the transaction.
+//
+// Failures matching the retry policy of the Config roll back the
+// transaction and run fn again.
 func (db *Pgr) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
-	return db.conn.BeginFunc(ctx, func(tx pgx.Tx) error {
-		ctx = setTransaction(ctx, tx)
-		return fn(ctx)
-	})
+	return db.TransactionWith(ctx, pgx.TxOptions{}, fn)
 }
 
-func getTransaction(ctx context.Context) pgx.Tx {
-	if tx, ok := ctx.Value(transactionKey).(pgx.Tx); ok {
-		return tx
+// TransactionWith is like Transaction, with the isolation level, access mode
+// and deferrable mode of opts. The connection must implement
+// BeginTx(ctx, pgx.TxOptions) unless opts is empty.
+//
+// When the context already carries a transaction, fn runs in a savepoint of
+// it instead: an error of fn only rolls back to the savepoint, and opts are
+// ignored since they are those of the outermost transaction.
+func (db *Pgr) TransactionWith(ctx context.Context, opts pgx.TxOptions, fn func(ctx context.Context) error) (err error) {
+	ctx, span := db.startSpan(ctx, "pgr.transaction", Attribute{AttrOperation, "transaction"})
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	policy := db.retry
+	if getTransaction(ctx) != nil {
+		// only the outermost transaction can be retried
+		policy.MaxAttempts = 0
+	}
+	for attempt := 1; ; attempt++ {
+		err = db.transaction(ctx, opts, fn)
+		if err == nil || attempt >= policy.MaxAttempts || !policy.retryable(err) {
+			return pgError(err)
+		}
+
+		delay := policy.delay(attempt)
+		db.logger.Log(ctx, LogLevelWarn, "transaction retry",
+			ErrorField(err),
+			Int64Field("attempt", int64(attempt)),
+			DurationField("delay", delay),
+		)
+		select {
+		case <-ctx.Done():
+			return pgError(err)
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (db *Pgr) begin(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
+	if outer := getTransaction(ctx); outer != nil {
+		// SAVEPOINT, released on commit and rolled back to on rollback
+		return outer.Begin(ctx)
+	}
+	if opts == (pgx.TxOptions{}) {
+		return db.conn.Begin(ctx)
+	}
+	if conn, ok := db.conn.(txBeginner); ok {
+		return conn.BeginTx(ctx, opts)
+	}
+	return nil, ErrNotSupported
+}
+
+func (db *Pgr) transaction(ctx context.Context, opts pgx.TxOptions, fn func(ctx context.Context) error) (err error) {
+	tx, err := db.begin(ctx, opts)
+	if err != nil {
+		return err
+	}
+	state := &txState{
+		tx:     tx,
+		parent: getTxState(ctx),
 	}
+	defer func() {
+		rollbackErr := tx.Rollback(ctx)
+		if rollbackErr != nil && !errors.Is(rollbackErr, pgx.ErrTxClosed) {
+			err = rollbackErr
+		}
+	}()
+
+	if db.defaultTimeout > 0 && state.parent == nil {
+		_, err := tx.Exec(ctx, "SET LOCAL statement_timeout = "+strconv.FormatInt(db.defaultTimeout.Milliseconds(), 10))
+		if err != nil {
+			state.rolledBack(ctx)
+			return err
+		}
+	}
+
+	err = fn(context.WithValue(ctx, transactionKey, state))
+	if err != nil {
+		tx.Rollback(ctx)
+		state.rolledBack(ctx)
+		return err
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		state.rolledBack(ctx)
+		return err
+	}
+	if state.parent == nil {
+		db.trackWrite(ctx)
+	}
+	state.committed(ctx)
 	return nil
 }
 
-func setTransaction(ctx context.Context, tx pgx.Tx) context.Context {
-	return context.WithValue(ctx, transactionKey, tx)
+// txState is the transaction carried by the context,
+// with the hooks registered while it runs.
+type txState struct {
+	tx            pgx.Tx
+	parent        *txState
+	afterCommit   []func(ctx context.Context)
+	afterRollback []func(ctx context.Context)
+	// afterEnd are the after rollback hooks of the savepoints rolled back,
+	// which run when the outermost transaction ends either way.
+	afterEnd []func(ctx context.Context)
+}
+
+// committed runs the after commit hooks of the outermost transaction.
+// The hooks of a savepoint are handed to its parent.
+func (s *txState) committed(ctx context.Context) {
+	if s.parent != nil {
+		s.parent.afterCommit = append(s.parent.afterCommit, s.afterCommit...)
+		s.parent.afterRollback = append(s.parent.afterRollback, s.afterRollback...)
+		s.parent.afterEnd = append(s.parent.afterEnd, s.afterEnd...)
+		return
+	}
+	runHooks(ctx, s.afterEnd)
+	runHooks(ctx, s.afterCommit)
+}
+
+// rolledBack runs the after rollback hooks of the outermost transaction, and
+// drops the after commit ones. The after rollback hooks of a savepoint are
+// handed to its parent, to run when the outermost transaction ends.
+func (s *txState) rolledBack(ctx context.Context) {
+	if s.parent != nil {
+		s.parent.afterEnd = append(s.parent.afterEnd, s.afterEnd...)
+		s.parent.afterEnd = append(s.parent.afterEnd, s.afterRollback...)
+		return
+	}
+	runHooks(ctx, s.afterEnd)
+	runHooks(ctx, s.afterRollback)
+}
+
+func runHooks(ctx context.Context, hooks []func(ctx context.Context)) {
+	ctx = context.WithValue(ctx, transactionKey, (*txState)(nil))
+	for _, hook := range hooks {
+		hook(ctx)
+	}
+}
+
+// AfterCommit registers fn to run once the outermost transaction of the
+// context commits. fn is dropped if the transaction, or the savepoint it was
+// registered in, rolls back.
+// Without a transaction, fn runs immediately.
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	s := getTxState(ctx)
+	if s == nil {
+		fn(ctx)
+		return
+	}
+	s.afterCommit = append(s.afterCommit, fn)
+}
+
+// AfterRollback registers fn to run once the outermost transaction of the
+// context rolls back. If the savepoint it was registered in rolls back, fn
+// runs once the outermost transaction ends, whether it commits or not,
+// since the work of the savepoint is lost anyway.
+// Without a transaction, fn never runs.
+func AfterRollback(ctx context.Context, fn func(ctx context.Context)) {
+	s := getTxState(ctx)
+	if s == nil {
+		return
+	}
+	s.afterRollback = append(s.afterRollback, fn)
+}
+
+func getTxState(ctx context.Context) *txState {
+	s, _ := ctx.Value(transactionKey).(*txState)
+	return s
+}
+
+func getTransaction(ctx context.Context) pgx.Tx {
+	if s := getTxState(ctx); s != nil {
+		return s.tx
+	}
+	return nil
 }
@@ -0,0 +1,214 @@
+package pgr
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionRetry(t *testing.T) {
+	db := getDb()
+	db.retry = RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+	}
+	ctx := context.Background()
+
+	attempts := 0
+	err := db.Transaction(ctx, func(ctx context.Context) error {
+		attempts++
+		_, err := db.InsertInto("users").Pair("name", "a").Pair("age", attempts).Exec(ctx)
+		require.NoError(t, err)
+		if attempts < 3 {
+			return &pgconn.PgError{Code: "40001"}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, attempts)
+
+	ages, err := All[int](ctx, db.Select("age").From("users"))
+	require.NoError(t, err)
+	require.Equal(t, []int{3}, ages)
+
+	attempts = 0
+	err = db.Transaction(ctx, func(ctx context.Context) error {
+		attempts++
+		return &pgconn.PgError{Code: "23505"}
+	})
+	require.Error(t, err)
+	require.Equal(t, 1, attempts)
+}
+
+func TestTransactionTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("query", func(t *testing.T) {
+		db := getDb()
+		db.defaultTimeout = 10 * time.Millisecond
+
+		err := db.Transaction(ctx, func(ctx context.Context) error {
+			_, err := All[string](ctx, db.SelectSql("SELECT pg_sleep(1)::text"))
+			return err
+		})
+		require.ErrorIs(t, err, ErrStatementTimeout)
+		require.ErrorIs(t, err, ErrQueryCanceled)
+	})
+
+	t.Run("exec", func(t *testing.T) {
+		db := getDb()
+		db.defaultTimeout = 10 * time.Millisecond
+
+		err := db.Transaction(ctx, func(ctx context.Context) error {
+			_, err := db.UpdateSql("UPDATE users SET age = 1 WHERE pg_sleep(1) IS NOT NULL").Exec(ctx)
+			return err
+		})
+		require.ErrorIs(t, err, ErrStatementTimeout)
+	})
+}
+
+func TestTransactionWith(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("options", func(t *testing.T) {
+		conn, err := pgx.Connect(ctx, DB_URL)
+		require.NoError(t, err)
+		defer conn.Close(ctx)
+		db, err := New(conn, nil)
+		require.NoError(t, err)
+
+		opts := pgx.TxOptions{
+			IsoLevel:       pgx.Serializable,
+			AccessMode:     pgx.ReadOnly,
+			DeferrableMode: pgx.Deferrable,
+		}
+		err = db.TransactionWith(ctx, opts, func(ctx context.Context) error {
+			isolation, err := Scalar[string](ctx, db.SelectSql("SHOW transaction_isolation"))
+			require.NoError(t, err)
+			require.Equal(t, "serializable", isolation)
+
+			readOnly, err := Scalar[string](ctx, db.SelectSql("SHOW transaction_read_only"))
+			require.NoError(t, err)
+			require.Equal(t, "on", readOnly)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("not supported", func(t *testing.T) {
+		db := getDb()
+
+		called := false
+		err := db.TransactionWith(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable}, func(ctx context.Context) error {
+			called = true
+			return nil
+		})
+		require.ErrorIs(t, err, ErrNotSupported)
+		require.False(t, called)
+	})
+}
+
+func TestNestedTransaction(t *testing.T) {
+	db := getDb()
+	ctx := context.Background()
+	failed := errors.New("failed")
+
+	err := db.Transaction(ctx, func(ctx context.Context) error {
+		_, err := db.InsertInto("users").Pair("name", "outer").Pair("age", 1).Exec(ctx)
+		require.NoError(t, err)
+
+		err = db.Transaction(ctx, func(ctx context.Context) error {
+			_, err := db.InsertInto("users").Pair("name", "inner").Pair("age", 2).Exec(ctx)
+			require.NoError(t, err)
+			return failed
+		})
+		require.ErrorIs(t, err, failed)
+
+		return db.Transaction(ctx, func(ctx context.Context) error {
+			_, err := db.InsertInto("users").Pair("name", "released").Pair("age", 3).Exec(ctx)
+			return err
+		})
+	})
+	require.NoError(t, err)
+
+	names, err := All[string](ctx, db.Select("name").From("users").OrderAsc("age"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"outer", "released"}, names)
+}
+
+func TestTransactionHooks(t *testing.T) {
+	db := getDb()
+	ctx := context.Background()
+	failed := errors.New("failed")
+
+	var events []string
+	hook := func(event string) func(context.Context) {
+		return func(ctx context.Context) {
+			require.Nil(t, getTransaction(ctx))
+			events = append(events, event)
+		}
+	}
+
+	err := db.Transaction(ctx, func(ctx context.Context) error {
+		AfterCommit(ctx, hook("outer commit"))
+		AfterRollback(ctx, hook("outer rollback"))
+
+		err := db.Transaction(ctx, func(ctx context.Context) error {
+			AfterCommit(ctx, hook("failed commit"))
+			AfterRollback(ctx, hook("failed rollback"))
+			return failed
+		})
+		require.ErrorIs(t, err, failed)
+
+		err = db.Transaction(ctx, func(ctx context.Context) error {
+			AfterCommit(ctx, hook("released commit"))
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.Empty(t, events)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"failed rollback", "outer commit", "released commit"}, events)
+
+	events = nil
+	err = db.Transaction(ctx, func(ctx context.Context) error {
+		AfterCommit(ctx, hook("commit"))
+		AfterRollback(ctx, hook("rollback"))
+		return failed
+	})
+	require.ErrorIs(t, err, failed)
+	require.Equal(t, []string{"rollback"}, events)
+
+	events = nil
+	err = db.Transaction(ctx, func(ctx context.Context) error {
+		AfterRollback(ctx, hook("outer rollback"))
+		err := db.Transaction(ctx, func(ctx context.Context) error {
+			AfterRollback(ctx, hook("inner rollback"))
+			return failed
+		})
+		require.ErrorIs(t, err, failed)
+		require.Empty(t, events)
+		return failed
+	})
+	require.ErrorIs(t, err, failed)
+	require.Equal(t, []string{"inner rollback", "outer rollback"}, events)
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{
+		BaseDelay: 100 * time.Millisecond,
+		MaxDelay:  300 * time.Millisecond,
+	}
+	for attempt, max := range []time.Duration{100, 200, 300, 300} {
+		delay := policy.delay(attempt + 1)
+		require.GreaterOrEqual(t, delay, max*time.Millisecond/2)
+		require.LessOrEqual(t, delay, max*time.Millisecond)
+	}
+}
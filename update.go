@@ -2,23 +2,38 @@ package pgr
 
 import (
 	"context"
+	"reflect"
+	"time"
 )
 
 type UpdateBuilder struct {
 	db *Pgr
 	raw
 
+	timeout time.Duration
+
 	table        string
+	columns      []string
 	value        map[string]interface{}
+	record       interface{}
+	onlyColumns  []string
+	omitColumns  []string
+	fromTables   []interface{}
 	whereCond    []Builder
+	order        []Builder
+	limitCount   int64
+	skipLocked   bool
 	returnColumn []string
+	allRows      bool
+	noChanges    bool
 }
 
 func (db *Pgr) Update(table string) *UpdateBuilder {
 	return &UpdateBuilder{
-		table: table,
-		value: make(map[string]interface{}),
-		db:    db,
+		table:      table,
+		value:      make(map[string]interface{}),
+		limitCount: -1,
+		db:         db,
 	}
 }
 
@@ -28,11 +43,20 @@ func (db *Pgr) UpdateSql(query string, value ...interface{}) *UpdateBuilder {
 			Query: query,
 			Value: value,
 		},
-		value: make(map[string]interface{}),
-		db:    db,
+		value:      make(map[string]interface{}),
+		limitCount: -1,
+		db:         db,
 	}
 }
 
+// From adds a table to the FROM clause, so that rows of other tables can be
+// used in the where condition and in the new values.
+// table can be Builder or string.
+func (b *UpdateBuilder) From(table interface{}) *UpdateBuilder {
+	b.fromTables = append(b.fromTables, table)
+	return b
+}
+
 // Where adds a where condition.
 // query can be Builder or string. value is used only if query type is string.
 func (b *UpdateBuilder) Where(query interface{}, value ...interface{}) *UpdateBuilder {
@@ -53,6 +77,9 @@ func (b *UpdateBuilder) Returning(column ...string) *UpdateBuilder {
 
 // Set updates column with value.
 func (b *UpdateBuilder) Set(column string, value interface{}) *UpdateBuilder {
+	if _, ok := b.value[column]; !ok {
+		b.columns = append(b.columns, column)
+	}
 	b.value[column] = value
 	return b
 }
@@ -65,17 +92,175 @@ func (b *UpdateBuilder) SetMap(m map[string]interface{}) *UpdateBuilder {
 	return b
 }
 
+// Record is a helper function to update a single record, using a struct as the value.
+//
+// Every struct field becomes a SET assignment, except the primary key which is
+// used to add `WHERE pk = ?` instead. The primary key is the field tagged with
+// `db:"column,pk"`, or the "id" column if no field is tagged.
+// Columns given to Set take precedence over the struct fields.
+func (b *UpdateBuilder) Record(record interface{}) *UpdateBuilder {
+	b.record = record
+	return b
+}
+
+// Only restricts the columns set by Record to the given ones.
+func (b *UpdateBuilder) Only(columns ...string) *UpdateBuilder {
+	if b.onlyColumns == nil {
+		b.onlyColumns = []string{}
+	}
+	b.onlyColumns = append(b.onlyColumns, columns...)
+	return b
+}
+
+// Omit excludes the given columns from the ones set by Record.
+func (b *UpdateBuilder) Omit(columns ...string) *UpdateBuilder {
+	b.omitColumns = append(b.omitColumns, columns...)
+	return b
+}
+
+// Changes updates the columns of the tracked record that changed since the
+// snapshot was taken. When none changed, Exec and Load do nothing.
+func (b *UpdateBuilder) Changes(s *Snapshot) *UpdateBuilder {
+	changed := s.Changed()
+	b.noChanges = len(changed) == 0
+	return b.Record(s.record).Only(changed...)
+}
+
+// recordValues returns the columns and values to set from the record, and
+// the condition on its primary key.
+func (b *UpdateBuilder) recordValues() ([]string, []interface{}, Builder, error) {
+	v := reflect.Indirect(reflect.ValueOf(b.record))
+	if v.Kind() != reflect.Struct {
+		return nil, nil, nil, ErrNotSupported
+	}
+
+	s := newTagStore()
+	pk, ok := s.tagged(v.Type(), "pk")
+	if !ok {
+		pk = "id"
+	}
+
+	var columns []string
+	for _, field := range s.get(v.Type()) {
+		if field == "" || field == pk {
+			continue
+		}
+		if _, ok := b.value[field]; ok {
+			continue
+		}
+		if b.onlyColumns != nil && !contains(b.onlyColumns, field) {
+			continue
+		}
+		if contains(b.omitColumns, field) {
+			continue
+		}
+		columns = append(columns, field)
+	}
+
+	found := make([]interface{}, len(columns)+1)
+	s.findValueByName(v, append(columns, pk), found, false)
+	if found[len(columns)] == nil {
+		return nil, nil, nil, ErrPrimaryKeyNotFound
+	}
+
+	value := make([]interface{}, len(columns))
+	for i := range columns {
+		if found[i] != nil {
+			value[i] = found[i].(reflect.Value).Interface()
+		}
+	}
+	cond := Eq(pk, found[len(columns)].(reflect.Value).Interface())
+	return columns, value, cond, nil
+}
+
+// OrderAsc adds a column to the ORDER BY clause used with Limit.
+func (b *UpdateBuilder) OrderAsc(col string) *UpdateBuilder {
+	b.order = append(b.order, order(col, asc))
+	return b
+}
+
+// OrderDesc adds a column to the ORDER BY clause used with Limit.
+func (b *UpdateBuilder) OrderDesc(col string) *UpdateBuilder {
+	b.order = append(b.order, order(col, desc))
+	return b
+}
+
+// OrderBy specifies columns for ordering the rows affected with Limit.
+func (b *UpdateBuilder) OrderBy(col string) *UpdateBuilder {
+	b.order = append(b.order, Expr(col))
+	return b
+}
+
+// Limit restricts the statement to the first n rows matching the where
+// condition. Since postgres has no LIMIT for this statement, the condition is
+// rewritten to `(tableoid, ctid) IN (SELECT tableoid, ctid ... LIMIT n)`.
+// It can't be used together with From.
+func (b *UpdateBuilder) Limit(n uint64) *UpdateBuilder {
+	b.limitCount = int64(n)
+	return b
+}
+
+// SkipLocked locks the rows selected by Limit with FOR UPDATE SKIP LOCKED,
+// so that concurrent statements work on different rows instead of waiting.
+func (b *UpdateBuilder) SkipLocked() *UpdateBuilder {
+	b.skipLocked = true
+	return b
+}
+
+// limited reports whether the condition has to be rewritten to
+// honour Limit, OrderBy or SkipLocked.
+func (b *UpdateBuilder) limited() bool {
+	return b.limitCount >= 0 || len(b.order) > 0 || b.skipLocked
+}
+
+// AllRows allows the statement to run without a where condition,
+// affecting all rows of the table.
+func (b *UpdateBuilder) AllRows() *UpdateBuilder {
+	b.allRows = true
+	return b
+}
+
 func (b *UpdateBuilder) Exec(ctx context.Context) (int64, error) {
+	if b.unchanged() {
+		return 0, nil
+	}
 	return b.db.exec(ctx, b)
 }
 
 func (b *UpdateBuilder) Load(ctx context.Context, value interface{}) error {
+	if b.unchanged() {
+		return nil
+	}
 	_, err := b.db.query(ctx, b, value)
 	return err
 }
 
+// unchanged reports whether the statement has nothing to set,
+// because the record given to Changes was not modified.
+func (b *UpdateBuilder) unchanged() bool {
+	return b.noChanges && len(b.columns) == 0
+}
+
+// Timeout sets the statement timeout of the query, which is enforced by the
+// server and reported as ErrStatementTimeout.
+func (b *UpdateBuilder) Timeout(d time.Duration) *UpdateBuilder {
+	b.timeout = d
+	return b
+}
+
+func (b *UpdateBuilder) statementTimeout() time.Duration {
+	return b.timeout
+}
+
+func (b *UpdateBuilder) pgr() *Pgr {
+	return b.db
+}
+
 func (b *UpdateBuilder) Build(buf Buffer) error {
 	if b.raw.Query != "" {
+		if b.db != nil && b.db.requireWhere && !b.allRows && !hasWhere(b.raw.Query) {
+			return ErrMissingWhere
+		}
 		return b.raw.Build(buf)
 	}
 
@@ -83,16 +268,43 @@ func (b *UpdateBuilder) Build(buf Buffer) error {
 		return ErrTableNotSpecified
 	}
 
-	if len(b.value) == 0 {
+	columns := b.columns
+	value := make([]interface{}, len(columns))
+	for i, col := range columns {
+		value[i] = b.value[col]
+	}
+	whereCond := b.whereCond
+
+	if b.record != nil {
+		recordColumns, recordValue, pkCond, err := b.recordValues()
+		if err != nil {
+			return err
+		}
+		columns = append(columns[:len(columns):len(columns)], recordColumns...)
+		value = append(value, recordValue...)
+		whereCond = append(whereCond[:len(whereCond):len(whereCond)], pkCond)
+	}
+
+	if len(columns) == 0 {
 		return ErrColumnNotSpecified
 	}
 
+	if len(whereCond) == 0 && !b.allRows {
+		return ErrMissingWhere
+	}
+
+	if b.limited() {
+		if len(b.fromTables) > 0 {
+			return ErrNotSupported
+		}
+		whereCond = []Builder{limitRows(b.table, whereCond, b.order, b.limitCount, b.skipLocked)}
+	}
+
 	buf.WriteString("UPDATE ")
 	buf.WriteString(QuoteIdent(b.table))
 	buf.WriteString(" SET ")
 
-	i := 0
-	for col, v := range b.value {
+	for i, col := range columns {
 		if i > 0 {
 			buf.WriteString(", ")
 		}
@@ -100,14 +312,17 @@ func (b *UpdateBuilder) Build(buf Buffer) error {
 		buf.WriteString(" = ")
 		buf.WriteString(placeholder)
 
-		buf.WriteValue(v)
+		buf.WriteValue(value[i])
+	}
 
-		i++
+	if len(b.fromTables) > 0 {
+		buf.WriteString(" FROM ")
+		buildTables(buf, b.fromTables)
 	}
 
-	if len(b.whereCond) > 0 {
+	if len(whereCond) > 0 {
 		buf.WriteString(" WHERE ")
-		err := And(b.whereCond...).Build(buf)
+		err := And(whereCond...).Build(buf)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,153 @@
+package pgr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateBuilder(t *testing.T) {
+	db := getDb()
+
+	t.Run("update from values", func(t *testing.T) {
+		buf := NewBuffer()
+		err := db.Update("users").
+			Set("name", Expr("v.name")).
+			From(Values(
+				[]interface{}{1, "a"},
+				[]interface{}{2, "b"},
+			).As("v", "id", "name")).
+			Where("users.id = v.id").
+			Build(buf)
+		require.NoError(t, err)
+		require.Equal(t, `UPDATE "users" SET "name" = ? FROM ? WHERE (users.id = v.id)`, buf.String())
+
+		query, err := Interpolate(buf.String(), buf.Value())
+		require.NoError(t, err)
+		require.Equal(t, `UPDATE "users" SET "name" = v.name `+
+			`FROM (VALUES (1,'a'), (2,'b')) AS "v" ("id","name") `+
+			`WHERE (users.id = v.id)`, query)
+	})
+
+	t.Run("update from subquery", func(t *testing.T) {
+		buf := NewBuffer()
+		err := db.Update("users").
+			Set("age", Expr("s.age")).
+			From(Select("id", "age").From("staging").As("s")).
+			Where(And(Expr("users.id = s.id"), Gt("s.age", 18))).
+			Build(buf)
+		require.NoError(t, err)
+
+		query, err := Interpolate(buf.String(), buf.Value())
+		require.NoError(t, err)
+		require.Equal(t, `UPDATE "users" SET "age" = s.age `+
+			`FROM (SELECT id, age FROM staging) AS "s" `+
+			`WHERE ((users.id = s.id) AND ("s"."age" > 18))`, query)
+	})
+
+	t.Run("update with record", func(t *testing.T) {
+		type account struct {
+			Key  string `db:"key,pk"`
+			Name string `db:"name"`
+			Age  int    `db:"age"`
+			Role string `db:"role"`
+		}
+
+		buf := NewBuffer()
+		err := db.Update("accounts").
+			Record(&account{Key: "k", Name: "a", Age: 1, Role: "admin"}).
+			Omit("role").
+			Set("age", 2).
+			Build(buf)
+		require.NoError(t, err)
+		require.Equal(t, `UPDATE "accounts" SET "age" = ?, "name" = ? WHERE ("key" = ?)`, buf.String())
+		require.Equal(t, []interface{}{2, "a", "k"}, buf.Value())
+
+		buf = NewBuffer()
+		err = db.Update("accounts").
+			Record(account{Key: "k", Name: "a", Age: 1}).
+			Only("age").
+			Build(buf)
+		require.NoError(t, err)
+		require.Equal(t, `UPDATE "accounts" SET "age" = ? WHERE ("key" = ?)`, buf.String())
+		require.Equal(t, []interface{}{1, "k"}, buf.Value())
+	})
+
+	t.Run("update changes", func(t *testing.T) {
+		user := User{Id: 1, Name: "a", Age: 1}
+		snapshot, err := Track(&user)
+		require.NoError(t, err)
+		user.Age = 2
+
+		require.Equal(t, []string{"age"}, snapshot.Changed())
+
+		buf := NewBuffer()
+		err = db.Update("users").Changes(snapshot).Build(buf)
+		require.NoError(t, err)
+		require.Equal(t, `UPDATE "users" SET "age" = ? WHERE ("id" = ?)`, buf.String())
+		require.Equal(t, []interface{}{2, int64(1)}, buf.Value())
+	})
+
+	t.Run("update without changes", func(t *testing.T) {
+		user := User{Id: 1, Name: "a", Age: 1}
+		snapshot, err := Track(&user)
+		require.NoError(t, err)
+
+		count, err := db.Update("users").Changes(snapshot).Exec(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, int64(0), count)
+
+		_, err = Track(nil)
+		require.ErrorIs(t, err, ErrInvalidPointer)
+		_, err = Track(user)
+		require.ErrorIs(t, err, ErrInvalidPointer)
+	})
+
+	t.Run("update without where", func(t *testing.T) {
+		err := db.Update("users").Set("age", 1).Build(NewBuffer())
+		require.ErrorIs(t, err, ErrMissingWhere)
+
+		buf := NewBuffer()
+		err = db.Update("users").Set("age", 1).AllRows().Build(buf)
+		require.NoError(t, err)
+		require.Equal(t, `UPDATE "users" SET "age" = ?`, buf.String())
+	})
+
+	t.Run("raw update without where", func(t *testing.T) {
+		strict, err := New(db.Conn(), &Config{RequireWhere: true})
+		require.NoError(t, err)
+
+		err = strict.UpdateSql("UPDATE users SET name = 'where'").Build(NewBuffer())
+		require.ErrorIs(t, err, ErrMissingWhere)
+
+		err = strict.UpdateSql("UPDATE users SET age = (SELECT age FROM users u WHERE u.id = 1)").Build(NewBuffer())
+		require.ErrorIs(t, err, ErrMissingWhere)
+
+		err = strict.UpdateSql("UPDATE users SET age = 1 /* where id = 1 */").Build(NewBuffer())
+		require.ErrorIs(t, err, ErrMissingWhere)
+
+		err = strict.UpdateSql("UPDATE users SET name = 'a' WHERE id = ?", 1).Build(NewBuffer())
+		require.NoError(t, err)
+
+		err = strict.UpdateSql("UPDATE users SET age = (SELECT 1) -- comment\nWHERE id = ?", 1).Build(NewBuffer())
+		require.NoError(t, err)
+
+		err = db.UpdateSql("UPDATE users SET name = 'a'").Build(NewBuffer())
+		require.NoError(t, err)
+	})
+
+	t.Run("update with limit", func(t *testing.T) {
+		buf := NewBuffer()
+		err := db.Update("users").
+			Set("age", 1).
+			AllRows().
+			OrderBy("id").
+			Limit(10).
+			Build(buf)
+		require.NoError(t, err)
+		require.Equal(t, `UPDATE "users" SET "age" = ? `+
+			`WHERE ((tableoid, ctid) IN (SELECT tableoid, ctid FROM "users" ORDER BY id LIMIT 10))`, buf.String())
+		require.Equal(t, []interface{}{1}, buf.Value())
+	})
+}
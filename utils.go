@@ -3,6 +3,7 @@ package pgr
 import (
 	"database/sql/driver"
 	"reflect"
+	"regexp"
 	"strings"
 )
 
@@ -69,7 +70,7 @@ func (s *tagStore) get(t reflect.Type) []string {
 				// unexported
 				continue
 			}
-			tag := field.Tag.Get("db")
+			tag, _ := parseTag(field.Tag.Get("db"))
 			if tag == "-" {
 				// ignore
 				continue
@@ -85,6 +86,33 @@ func (s *tagStore) get(t reflect.Type) []string {
 	return s.m[t]
 }
 
+// tagged returns the column of the first field in t whose db tag
+// has the given option, e.g. `db:"id,pk"`.
+func (s *tagStore) tagged(t reflect.Type, option string) (string, bool) {
+	if t.Kind() != reflect.Struct {
+		return "", false
+	}
+	l := s.get(t)
+	for i := 0; i < t.NumField(); i++ {
+		if l[i] == "" {
+			continue
+		}
+		_, opts := parseTag(t.Field(i).Tag.Get("db"))
+		for _, opt := range opts {
+			if opt == option {
+				return l[i], true
+			}
+		}
+	}
+	return "", false
+}
+
+// parseTag splits a db tag into the column name and its options.
+func parseTag(tag string) (string, []string) {
+	part := strings.Split(tag, ",")
+	return part[0], part[1:]
+}
+
 func (s *tagStore) findPtr(value reflect.Value, name []string, ptr []interface{}) error {
 	if value.CanAddr() && value.Addr().Type().Implements(typeScanner) {
 		ptr[0] = value.Addr().Interface()
@@ -139,3 +167,38 @@ func (s *tagStore) findValueByName(value reflect.Value, name []string, ret []int
 		}
 	}
 }
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	quotedRegexp = regexp.MustCompile(`'(?:[^']|'')*'|"(?:[^"]|"")*"|--[^\n]*|/\*[\s\S]*?\*/`)
+	whereRegexp  = regexp.MustCompile(`(?i)\bwhere\b`)
+)
+
+// hasWhere reports whether a raw query contains a top-level WHERE clause,
+// ignoring string literals, quoted identifiers, comments and subqueries.
+func hasWhere(query string) bool {
+	query = quotedRegexp.ReplaceAllString(query, " ")
+	top := make([]byte, 0, len(query))
+	depth := 0
+	for i := 0; i < len(query); i++ {
+		switch c := query[i]; {
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0:
+			top = append(top, c)
+			continue
+		}
+		top = append(top, ' ')
+	}
+	return whereRegexp.Match(top)
+}
@@ -0,0 +1,63 @@
+package pgr
+
+// ValuesBuilder builds a `VALUES (...), (...)` list.
+type ValuesBuilder struct {
+	rows [][]interface{}
+}
+
+// Values builds a `VALUES (...), (...)` list.
+// Use As to give it an alias and column names, so that it can be used
+// as a table in Select From, Update From and Delete Using.
+func Values(rows ...[]interface{}) *ValuesBuilder {
+	return &ValuesBuilder{
+		rows: rows,
+	}
+}
+
+func (v *ValuesBuilder) Build(buf Buffer) error {
+	if len(v.rows) == 0 {
+		return ErrInvalidSliceLength
+	}
+
+	buf.WriteString("VALUES ")
+	for i, row := range v.rows {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		if len(row) == 0 {
+			return ErrColumnNotSpecified
+		}
+		buf.WriteString("(")
+		for j := range row {
+			if j > 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString(placeholder)
+		}
+		buf.WriteString(")")
+		buf.WriteValue(row...)
+	}
+	return nil
+}
+
+// As creates an alias for the values list, with optional column names.
+func (v *ValuesBuilder) As(alias string, columns ...string) Builder {
+	return BuildFunc(func(buf Buffer) error {
+		buf.WriteString("(")
+		buf.WriteString(placeholder)
+		buf.WriteValue(v)
+		buf.WriteString(") AS ")
+		buf.WriteString(QuoteIdent(alias))
+		if len(columns) > 0 {
+			buf.WriteString(" (")
+			for i, col := range columns {
+				if i > 0 {
+					buf.WriteString(",")
+				}
+				buf.WriteString(QuoteIdent(col))
+			}
+			buf.WriteString(")")
+		}
+		return nil
+	})
+}